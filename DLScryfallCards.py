@@ -66,7 +66,9 @@ with open(BulkDataArenaPath, 'r', encoding="utf8") as file:
 		if c['arena_id'] not in cards:
 			cards[c['arena_id']] = {}
 		if c['lang'] == 'en':
-			selection = {key:value for key,value in c.items() if key in {'name', 'set', 'cmc', 'rarity', 'collector_number', 'color_identity'}}
+			selection = {key:value for key,value in c.items() if key in {'name', 'set', 'cmc', 'type_line', 'rarity', 'collector_number', 'color_identity', 'colors'}}
+			if 'colors' not in selection and 'card_faces' in c and 'colors' in c['card_faces'][0]:
+				selection['colors'] = c['card_faces'][0]['colors']
 			if c['arena_id'] in NonBoosterCards:
 				selection['in_booster'] = False;
 			if 'image_uris' in c and 'border_crop' in c['image_uris']:
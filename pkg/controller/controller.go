@@ -1,7 +1,10 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
@@ -14,8 +17,16 @@ type Storage interface {
 	CreateSession(session.Options) (string, error)
 	GetSession(string) (*session.Session, error)
 	AddPlayer(string, session.PlayerRegistration) (string, *session.Session, error)
-	RemovePlayer(string, string) *session.Session
+	RemovePlayer(session.CardDB, string, string) *session.Session
 	UpdatePlayer(session.CardDB, string, string, session.PlayerUpdate) (*session.Session, error)
+	HandleAction(session.CardDB, string, string, session.PlayerAction) (*session.Session, error)
+	Expire(session.CardDB, string) (*session.Session, bool, error)
+
+	CreateCube(session.Cube) (string, error)
+	ListCubes() ([]session.Cube, error)
+	GetCube(string) (*session.Cube, error)
+	UpdateCube(string, session.Cube) (*session.Cube, error)
+	DeleteCube(string) (bool, error)
 }
 
 // Controller describes the behavior of the app
@@ -23,6 +34,9 @@ type Controller struct {
 	storage Storage
 	cardDB  session.CardDB
 	lobby   *lobby.Lobby
+
+	timerMutex sync.Mutex
+	timers     map[string]bool
 }
 
 // New initializes a fresh Controller with the given storage backend
@@ -36,6 +50,7 @@ func New(storage Storage, path string) (*Controller, error) {
 		storage: storage,
 		cardDB:  cardDB,
 		lobby:   lobby.New(),
+		timers:  map[string]bool{},
 	}, nil
 }
 
@@ -60,6 +75,19 @@ func (m *Controller) Router() http.Handler {
 	session.GET("/:sessionID", m.getSession)
 	session.GET("/:sessionID/players", m.getSessionWebSocket)
 	session.GET("/:sessionID/players/:playerID/collection", m.getSessionCollection)
+	session.GET("/:sessionID/players/:playerID/picks", m.getPlayerPicks)
+	session.GET("/:sessionID/picks", m.getPicks)
+
+	root.GET("/api/v1/features", m.getFeatures)
+
+	cube := root.Group("/api/v1/cubes")
+
+	cube.POST("", m.createCube)
+	cube.GET("", m.listCubes)
+
+	cube.GET("/:cubeID", m.getCube)
+	cube.PUT("/:cubeID", m.updateCube)
+	cube.DELETE("/:cubeID", m.deleteCube)
 
 	return router
 }
@@ -71,6 +99,37 @@ func (m *Controller) createSession(c *gin.Context) {
 		return
 	}
 
+	// saved cubes are copied into the session, later changes to the cube don't affect it
+	if opts.CubeID != "" {
+		cube, err := m.storage.GetCube(opts.CubeID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching cube"})
+			return
+		}
+		if cube == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "cube not found"})
+			return
+		}
+		opts.Cube = cube.List
+	}
+
+	if err := opts.Validate(m.cardDB); err != nil {
+		var unresolved *session.UnresolvedCardsError
+		if errors.As(err, &unresolved) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "unresolved": unresolved.Names})
+			return
+		}
+
+		var queryErr *session.QueryError
+		if errors.As(err, &queryErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "position": queryErr.Position})
+			return
+		}
+
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	sessionid, err := m.storage.CreateSession(opts)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not create session"})
@@ -127,57 +186,49 @@ func (m *Controller) getSessionWebSocket(c *gin.Context) {
 		return
 	}
 
-	if playerRegistration.Name == "" {
-		conn.WriteJSON(gin.H{"error": "no player name provided"})
-		return
-	}
-
-	if len(playerRegistration.Collection) == 0 {
-		conn.WriteJSON(gin.H{"error": "empty collection provided"})
-		return
-	}
-
-	// add player, broadcast change
-	playerID, s, err := m.storage.AddPlayer(sessionID, playerRegistration)
-	if err != nil {
-		conn.WriteJSON(gin.H{"error": "error adding player"})
-		return
+	var playerID string
+	var s *session.Session
+	if playerRegistration.PlayerID != "" {
+		playerID, s = m.rejoinPlayer(conn, sessionID, playerRegistration.PlayerID, playerRegistration.Token)
+	} else {
+		playerID, s = m.addPlayer(conn, sessionID, playerRegistration)
 	}
 	if s == nil {
-		conn.WriteJSON(gin.H{"error": "session not found"})
-		return
-	}
-	if s.Started {
-		conn.WriteJSON(gin.H{"error": "session already started"})
-		return
-	}
-	if playerID == "" {
-		conn.WriteJSON(gin.H{"error": "error adding player"})
 		return
 	}
 
-	// reply with player ID
-	conn.WriteJSON(gin.H{"id": playerID})
+	// reply with player ID and the token needed to return to the session
+	conn.WriteJSON(gin.H{"id": playerID, "token": s.Players[playerID].Token})
 
 	// add player to lobby
 	if err := m.lobby.RegisterConnection(sessionID, playerID, conn); err != nil {
-		conn.WriteJSON(gin.H{"error": "player already registered"})
+		conn.WriteJSON(gin.H{"error": "could not register player: " + err.Error()})
+
+		// a new player can't come back without a connection and would keep the session from starting
+		if playerRegistration.PlayerID == "" {
+			m.storage.RemovePlayer(m.cardDB, sessionID, playerID)
+		}
 		return
 	}
 
 	// broadcast change
-	m.lobby.Broadcast(sessionID, s)
+	m.broadcast(sessionID, s)
 
-	// read and distribute updates
+	// read and distribute updates and in-game actions
 	for {
-		var update session.PlayerUpdate
-		if err := conn.ReadJSON(&update); err != nil {
+		var message session.PlayerMessage
+		if err := conn.ReadJSON(&message); err != nil {
 			break
 		}
 
-		session, err := m.storage.UpdatePlayer(m.cardDB, sessionID, playerID, update)
+		var session *session.Session
+		if message.Action != "" {
+			session, err = m.storage.HandleAction(m.cardDB, sessionID, playerID, message.PlayerAction)
+		} else {
+			session, err = m.storage.UpdatePlayer(m.cardDB, sessionID, playerID, message.PlayerUpdate)
+		}
 		if err != nil {
-			conn.WriteJSON(gin.H{"error": "could not update player"})
+			conn.WriteJSON(gin.H{"error": "could not update player: " + err.Error()})
 			continue
 		}
 		if session == nil {
@@ -190,15 +241,155 @@ func (m *Controller) getSessionWebSocket(c *gin.Context) {
 		}
 
 		// broadcast change
-		m.lobby.Broadcast(sessionID, session)
-
+		m.broadcast(sessionID, session)
 	}
 
 	m.lobby.Unregister(sessionID, playerID)
-	s = m.storage.RemovePlayer(sessionID, playerID)
+
+	// players of asynchronous sessions keep their seat and can return later
+	if s.Options.Async {
+		return
+	}
+
+	// the others may have new packs in front of them, now that the seat is played for
+	s = m.storage.RemovePlayer(m.cardDB, sessionID, playerID)
 	if s != nil {
-		m.lobby.Broadcast(sessionID, s)
+		m.broadcast(sessionID, s)
+	}
+}
+
+// addPlayer adds a new player to the session, writes an error to the socket if that's not possible (nil session)
+func (m *Controller) addPlayer(conn *websocket.Conn, sessionID string, playerRegistration session.PlayerRegistration) (string, *session.Session) {
+	if playerRegistration.Name == "" {
+		conn.WriteJSON(gin.H{"error": "no player name provided"})
+		return "", nil
 	}
+
+	if len(playerRegistration.Collection) == 0 {
+		conn.WriteJSON(gin.H{"error": "empty collection provided"})
+		return "", nil
+	}
+
+	playerID, s, err := m.storage.AddPlayer(sessionID, playerRegistration)
+	if err != nil {
+		conn.WriteJSON(gin.H{"error": "error adding player"})
+		return "", nil
+	}
+	if s == nil {
+		conn.WriteJSON(gin.H{"error": "session not found"})
+		return "", nil
+	}
+	if s.Started {
+		conn.WriteJSON(gin.H{"error": "session already started"})
+		return "", nil
+	}
+	if playerID == "" {
+		conn.WriteJSON(gin.H{"error": "error adding player"})
+		return "", nil
+	}
+
+	// the lobby is closed once the last player left, asynchronous sessions can be joined afterwards
+	m.lobby.NewSession(sessionID)
+	return playerID, s
+}
+
+// rejoinPlayer returns a player to an asynchronous session, writes an error to the socket if that's not possible (nil session)
+func (m *Controller) rejoinPlayer(conn *websocket.Conn, sessionID string, playerID string, token string) (string, *session.Session) {
+	s, err := m.storage.GetSession(sessionID)
+	if err != nil {
+		conn.WriteJSON(gin.H{"error": "error fetching session"})
+		return "", nil
+	}
+	if s == nil {
+		conn.WriteJSON(gin.H{"error": "session not found"})
+		return "", nil
+	}
+
+	if err := s.Rejoin(playerID, token); err != nil {
+		conn.WriteJSON(gin.H{"error": "could not rejoin: " + err.Error()})
+		return "", nil
+	}
+
+	// the lobby is closed once the last player left
+	m.lobby.NewSession(sessionID)
+	return playerID, s
+}
+
+// broadcast sends the public session state to all players, followed by what only each player may see.
+// In-game frames carry a type, everything else on the socket is the session itself
+func (m *Controller) broadcast(sessionID string, s *session.Session) {
+	m.lobby.Broadcast(sessionID, s)
+
+	if !s.Started {
+		return
+	}
+
+	if len(s.Deadlines()) > 0 {
+		m.startTimer(sessionID)
+	}
+
+	m.lobby.BroadcastEach(sessionID, func(playerID string) interface{} {
+		view := s.View(playerID)
+		if view == nil {
+			return nil
+		}
+		return gin.H{"type": "view", "view": view}
+	})
+}
+
+// startTimer drives the pick time limits of a session from the server, until no more time limits are pending
+func (m *Controller) startTimer(sessionID string) {
+	m.timerMutex.Lock()
+	defer m.timerMutex.Unlock()
+
+	if m.timers[sessionID] {
+		return
+	}
+	m.timers[sessionID] = true
+
+	go func() {
+		defer func() {
+			m.timerMutex.Lock()
+			delete(m.timers, sessionID)
+			m.timerMutex.Unlock()
+		}()
+
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			s, changed, err := m.storage.Expire(m.cardDB, sessionID)
+			if err != nil || s == nil {
+				return
+			}
+
+			if changed {
+				m.broadcast(sessionID, s)
+			}
+
+			deadlines := s.Deadlines()
+			if len(deadlines) == 0 {
+				return
+			}
+
+			// clients count down from the server time, their clocks may be off
+			update := gin.H{"type": "remaining", "remaining": remaining(deadlines, time.Now())}
+			m.lobby.BroadcastEach(sessionID, func(string) interface{} { return update })
+		}
+	}()
+}
+
+// remaining converts deadlines to the seconds left for each player
+func remaining(deadlines map[string]time.Time, now time.Time) map[string]int {
+	seconds := make(map[string]int, len(deadlines))
+	for playerID, deadline := range deadlines {
+		left := int(deadline.Sub(now).Round(time.Second) / time.Second)
+		if left < 0 {
+			left = 0
+		}
+		seconds[playerID] = left
+	}
+	return seconds
 }
 
 func (m *Controller) getSessionCollection(c *gin.Context) {
@@ -229,12 +420,18 @@ func (m *Controller) getSessionCollection(c *gin.Context) {
 		return
 	}
 
-	player, ok := session.Players[playerID]
-	if !ok {
-		c.JSON(http.StatusNotFound, gin.H{"error": "player not found"})
+	collection, err := session.PlayerCollection(playerID, c.Query("token"))
+	if err != nil {
+		pickError(c, err)
+		return
 	}
 
-	c.JSON(http.StatusOK, player.SessionCollection)
+	c.JSON(http.StatusOK, collection)
+}
+
+// getFeatures tells clients which filters the card database supports, so they only offer those
+func (m *Controller) getFeatures(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"types": m.cardDB.HasTypes(), "colors": m.cardDB.HasColors()})
 }
 
 func getSessionID(params gin.Params) string {
@@ -243,3 +440,6 @@ func getSessionID(params gin.Params) string {
 func getPlayerID(params gin.Params) string {
 	return params.ByName("playerID")
 }
+func getCubeID(params gin.Params) string {
+	return params.ByName("cubeID")
+}
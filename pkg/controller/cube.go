@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kjeisy/arenawithfriends/pkg/session"
+)
+
+func (m *Controller) createCube(c *gin.Context) {
+	cube, ok := m.bindCube(c)
+	if !ok {
+		return
+	}
+
+	id, err := m.storage.CreateCube(cube)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not create cube"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": id})
+}
+
+func (m *Controller) listCubes(c *gin.Context) {
+	cubes, err := m.storage.ListCubes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching cubes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, cubes)
+}
+
+func (m *Controller) getCube(c *gin.Context) {
+	id := getCubeID(c.Params)
+	if id == "" {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "no cube ID provided"})
+		return
+	}
+
+	cube, err := m.storage.GetCube(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching cube"})
+		return
+	}
+	if cube == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "cube not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, cube)
+}
+
+func (m *Controller) updateCube(c *gin.Context) {
+	id := getCubeID(c.Params)
+	if id == "" {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "no cube ID provided"})
+		return
+	}
+
+	cube, ok := m.bindCube(c)
+	if !ok {
+		return
+	}
+
+	updated, err := m.storage.UpdateCube(id, cube)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not update cube"})
+		return
+	}
+	if updated == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "cube not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+func (m *Controller) deleteCube(c *gin.Context) {
+	id := getCubeID(c.Params)
+	if id == "" {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "no cube ID provided"})
+		return
+	}
+
+	found, err := m.storage.DeleteCube(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not delete cube"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "cube not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": id})
+}
+
+// bindCube reads and validates a cube from the request body; on failure, the response is already written
+func (m *Controller) bindCube(c *gin.Context) (session.Cube, bool) {
+	var cube session.Cube
+	if err := c.BindJSON(&cube); err != nil {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "no cube data provided"})
+		return cube, false
+	}
+
+	if err := cube.Validate(m.cardDB); err != nil {
+		var unresolved *session.UnresolvedCardsError
+		if errors.As(err, &unresolved) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "unresolved": unresolved.Names})
+			return cube, false
+		}
+
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return cube, false
+	}
+
+	return cube, true
+}
@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kjeisy/arenawithfriends/pkg/session"
+)
+
+func (m *Controller) getPicks(c *gin.Context) {
+	s, ok := m.pickSession(c)
+	if !ok {
+		return
+	}
+
+	picks, err := s.PickLog()
+	if err != nil {
+		pickError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, picks)
+}
+
+func (m *Controller) getPlayerPicks(c *gin.Context) {
+	playerID := getPlayerID(c.Params)
+	if playerID == "" {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "no player ID provided"})
+		return
+	}
+
+	s, ok := m.pickSession(c)
+	if !ok {
+		return
+	}
+
+	// player IDs are public, only the player may see their picks during the draft
+	if err := s.Authenticate(playerID, c.Query("token")); err != nil {
+		pickError(c, err)
+		return
+	}
+
+	picks, err := s.PlayerPicks(playerID)
+	if err != nil {
+		pickError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, picks)
+}
+
+// pickSession fetches the session of the request, writes an error response if there is none
+func (m *Controller) pickSession(c *gin.Context) (*session.Session, bool) {
+	sessionID := getSessionID(c.Params)
+	if sessionID == "" {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "no session ID provided"})
+		return nil, false
+	}
+
+	s, err := m.storage.GetSession(sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching session"})
+		return nil, false
+	}
+	if s == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		return nil, false
+	}
+
+	return s, true
+}
+
+func pickError(c *gin.Context, err error) {
+	switch err {
+	case session.ErrPlayerNotFound, session.ErrNoPickLog:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case session.ErrInvalidToken:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+	}
+}
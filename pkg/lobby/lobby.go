@@ -19,10 +19,14 @@ func New() *Lobby {
 	}
 }
 
+// NewSession opens the lobby of a session. Open lobbies are kept, so it can be called again when players return
 func (l *Lobby) NewSession(sessionID string) {
 	l.mutex.Lock()
 	defer l.mutex.Unlock()
 
+	if _, ok := l.sessions[sessionID]; ok {
+		return
+	}
 	l.sessions[sessionID] = map[string]*websocket.Conn{}
 }
 
@@ -59,6 +63,28 @@ func (l *Lobby) Broadcast(sessionID string, sessionData *session.Session) {
 	}
 }
 
+func (l *Lobby) BroadcastEach(sessionID string, message func(playerID string) interface{}) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	sessions, ok := l.sessions[sessionID]
+	if !ok {
+		return
+	}
+
+	for playerID, broadcastConn := range sessions {
+		data := message(playerID)
+		if data == nil {
+			continue
+		}
+
+		if err := broadcastConn.WriteJSON(data); err != nil {
+			broadcastConn.Close()
+			delete(l.sessions[sessionID], playerID)
+		}
+	}
+}
+
 func (l *Lobby) Unregister(sessionID string, playerID string) {
 	l.mutex.Lock()
 	defer l.mutex.Unlock()
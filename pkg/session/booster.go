@@ -0,0 +1,166 @@
+package session
+
+import "math/rand"
+
+// Pack defaults, loosely modeled after Arena sealed
+const (
+	DefaultPackCount      = 6
+	DefaultDraftPackCount = 3
+	DefaultPackSize       = 14
+
+	maxPackCount = 36
+	maxPackSize  = 30
+)
+
+// rarity order used when a slot can't be filled with its own rarity
+var rarities = []string{"common", "uncommon", "rare", "mythic"}
+
+// PackOptions describes how many boosters are generated per player and how many cards they contain
+type PackOptions struct {
+	Count int `firestore:"count" json:"count"`
+	Size  int `firestore:"size" json:"size"`
+}
+
+// Validate checks if the pack options are within sane limits (0 == default)
+func (p PackOptions) Validate() error {
+	if p.Count < 0 || p.Count > maxPackCount {
+		return ErrInvalidPackOptions
+	}
+	if p.Size < 0 || p.Size > maxPackSize {
+		return ErrInvalidPackOptions
+	}
+	return nil
+}
+
+func (p PackOptions) count(def int) int {
+	if p.Count == 0 {
+		return def
+	}
+	return p.Count
+}
+
+func (p PackOptions) size() int {
+	if p.Size == 0 {
+		return DefaultPackSize
+	}
+	return p.Size
+}
+
+// Pack is a single booster. Set is only given if all cards are from the same set
+type Pack struct {
+	Set   string    `firestore:"set" json:"set,omitempty"`
+	Cards []ArenaID `firestore:"cards" json:"cards"`
+}
+
+// Packs returns the cards of the given packs as a collection
+func Packs(packs []Pack) Collection {
+	out := Collection{}
+	for _, pack := range packs {
+		for _, arenaID := range pack.Cards {
+			out[arenaID]++
+		}
+	}
+	return out
+}
+
+// supply contains every available copy of a card, sorted by rarity
+type supply map[string][]ArenaID
+
+func newSupply(cardDB CardDB, c Collection) supply {
+	// sort to make draws only depend on the random source
+	s := supply{}
+	for _, arenaID := range c.ids() {
+		cardDetails, ok := cardDB[arenaID]
+		if !ok {
+			continue
+		}
+
+		for i := byte(0); i < c[arenaID]; i++ {
+			s[cardDetails.Rarity] = append(s[cardDetails.Rarity], arenaID)
+		}
+	}
+	return s
+}
+
+// cards returns all copies in the supply in a stable order
+func (s supply) cards() []ArenaID {
+	var out []ArenaID
+	for _, rarity := range rarities {
+		out = append(out, s[rarity]...)
+	}
+	return out
+}
+
+// draw removes a random card of the given rarity from the supply, skipping the excluded cards
+func (s supply) draw(rng *rand.Rand, rarity string, exclude map[ArenaID]bool) (ArenaID, bool) {
+	cards := s[rarity]
+
+	candidates := make([]int, 0, len(cards))
+	for i, arenaID := range cards {
+		if !exclude[arenaID] {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", false
+	}
+
+	i := candidates[rng.Intn(len(candidates))]
+	arenaID := cards[i]
+	cards[i] = cards[len(cards)-1]
+	s[rarity] = cards[:len(cards)-1]
+
+	return arenaID, true
+}
+
+// drawSlot fills a slot of the given rarity, falling back to any other rarity if it is depleted
+func (s supply) drawSlot(rng *rand.Rand, rarity string, mythicRate float64, exclude map[ArenaID]bool) (ArenaID, bool) {
+	if rarity == "rare" && rng.Float64() < mythicRate {
+		if arenaID, ok := s.draw(rng, "mythic", exclude); ok {
+			return arenaID, true
+		}
+	}
+
+	if arenaID, ok := s.draw(rng, rarity, exclude); ok {
+		return arenaID, true
+	}
+
+	for _, fallback := range rarities {
+		if arenaID, ok := s.draw(rng, fallback, exclude); ok {
+			return arenaID, true
+		}
+	}
+
+	return "", false
+}
+
+// generatePacks creates the given number of boosters out of the given collection
+func generatePacks(cardDB CardDB, c Collection, opts PackOptions, collation Collation, count int, rng *rand.Rand) ([]Pack, error) {
+	s := newSupply(cardDB, c)
+	slots := collation.slots(opts)
+	mythicRate := collation.mythicRate()
+
+	packs := make([]Pack, count)
+	for i := range packs {
+		packs[i].Cards = make([]ArenaID, 0, len(slots))
+
+		var exclude map[ArenaID]bool
+		if !collation.Duplicates {
+			exclude = map[ArenaID]bool{}
+		}
+
+		for _, rarity := range slots {
+			arenaID, ok := s.drawSlot(rng, rarity, mythicRate, exclude)
+			if !ok {
+				return nil, ErrNotEnoughCards
+			}
+			packs[i].Cards = append(packs[i].Cards, arenaID)
+
+			if exclude != nil {
+				exclude[arenaID] = true
+			}
+		}
+	}
+
+	return packs, nil
+}
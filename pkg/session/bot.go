@@ -0,0 +1,124 @@
+package session
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Bot strategies
+const (
+	// BotRarity picks the card of the highest rarity (default)
+	BotRarity = "rarity"
+	// BotColor settles on the two colors it picked most and prefers cards of those colors
+	BotColor = "color"
+	// BotRating picks the card with the highest rating in Options.BotRatings
+	BotRating = "rating"
+)
+
+// CardRatings maps card names to ratings for rating bots, higher is better
+type CardRatings map[string]float64
+
+const (
+	// botCommitPicks is the number of picks after which a color bot commits to its colors
+	botCommitPicks = 3
+	// maxBotSeats is the size of the largest table bots fill up, e.g. 1 player and 7 bots
+	maxBotSeats = 8
+)
+
+// BotStrategy decides which card a bot takes out of a pack
+type BotStrategy interface {
+	// Pick returns one of the cards of the pack, given the bot's previous picks
+	Pick(cardDB CardDB, opts Options, picks []ArenaID, pack []ArenaID) ArenaID
+}
+
+var botStrategies = map[string]BotStrategy{}
+
+func init() {
+	RegisterBotStrategy(BotRarity, rarityBot{})
+	RegisterBotStrategy(BotColor, colorBot{})
+	RegisterBotStrategy(BotRating, ratingBot{})
+}
+
+// RegisterBotStrategy makes a bot strategy available under the given name
+func RegisterBotStrategy(name string, strategy BotStrategy) {
+	botStrategies[name] = strategy
+}
+
+// LookupBotStrategy returns the bot strategy with the given name. No name is rarity
+func LookupBotStrategy(name string) (BotStrategy, bool) {
+	if name == "" {
+		name = BotRarity
+	}
+
+	strategy, ok := botStrategies[name]
+	return strategy, ok
+}
+
+// botID returns the player ID of the n-th bot (starting at 1)
+func botID(n int) string {
+	return fmt.Sprintf("bot-%d", n)
+}
+
+type rarityBot struct{}
+
+func (rarityBot) Pick(cardDB CardDB, opts Options, picks []ArenaID, pack []ArenaID) ArenaID {
+	return autoPick(cardDB, pack)
+}
+
+type colorBot struct{}
+
+func (colorBot) Pick(cardDB CardDB, opts Options, picks []ArenaID, pack []ArenaID) ArenaID {
+	if len(picks) < botCommitPicks {
+		return autoPick(cardDB, pack)
+	}
+
+	counts := map[string]int{}
+	for _, arenaID := range picks {
+		for _, color := range cardDB[arenaID].colors(ColorSourceColors) {
+			counts[color]++
+		}
+	}
+
+	colors := []string{"W", "U", "B", "R", "G"}
+	sort.SliceStable(colors, func(i, j int) bool {
+		return counts[colors[i]] > counts[colors[j]]
+	})
+	committed := map[string]bool{colors[0]: true, colors[1]: true}
+
+	// colorless cards fit into every deck
+	var onColor []ArenaID
+	for _, arenaID := range pack {
+		fits := true
+		for _, color := range cardDB[arenaID].colors(ColorSourceColors) {
+			fits = fits && committed[color]
+		}
+		if fits {
+			onColor = append(onColor, arenaID)
+		}
+	}
+
+	if len(onColor) == 0 {
+		return autoPick(cardDB, pack)
+	}
+	return autoPick(cardDB, onColor)
+}
+
+type ratingBot struct{}
+
+func (ratingBot) Pick(cardDB CardDB, opts Options, picks []ArenaID, pack []ArenaID) ArenaID {
+	// unrated cards count as 0, ties are broken like an auto-pick
+	var best []ArenaID
+	var bestRating float64
+	for _, arenaID := range pack {
+		rating := opts.BotRatings[cardDB[arenaID].Name]
+		switch {
+		case len(best) == 0 || rating > bestRating:
+			best = []ArenaID{arenaID}
+			bestRating = rating
+		case rating == bestRating:
+			best = append(best, arenaID)
+		}
+	}
+
+	return autoPick(cardDB, best)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"os"
+	"strings"
 )
 
 // CardDB contains all card details in an Arena-Centric format
@@ -14,9 +15,97 @@ type CardData struct {
 	Name            string   `json:"name"`
 	CMC             uint     `json:"cmc"`
 	ColorIdentity   []string `json:"color_identity"`
+	Colors          []string `json:"colors"`
 	Set             string   `json:"set"`
 	CollectorNumber string   `json:"collector_number"`
 	Rarity          string   `json:"rarity"`
+	TypeLine        string   `json:"type_line"`
+	InBooster       bool     `json:"in_booster"`
+}
+
+// UnmarshalJSON reads the card data; the card database only tags cards that are *not* in boosters
+func (c *CardData) UnmarshalJSON(data []byte) error {
+	type cardData CardData
+	card := cardData{InBooster: true}
+	if err := json.Unmarshal(data, &card); err != nil {
+		return err
+	}
+
+	*c = CardData(card)
+	return nil
+}
+
+// Types returns the lower-case words of the card's type line, e.g. ["legendary", "creature", "elf", "druid"]
+func (c CardData) Types() []string {
+	return strings.FieldsFunc(strings.ToLower(c.TypeLine), func(r rune) bool {
+		return r == ' ' || r == '/' || r == '—'
+	})
+}
+
+// HasTypes checks if the card database contains type lines at all; older databases come without them
+func (cardDB CardDB) HasTypes() bool {
+	for _, card := range cardDB {
+		if card.TypeLine != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// HasColors checks if the card database contains casting colors at all; older databases only have the color identity
+func (cardDB CardDB) HasColors() bool {
+	for _, card := range cardDB {
+		if card.Colors != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// colors returns the casting colors or the color identity.
+// Card databases without casting colors fall back to the color identity
+func (c CardData) colors(source string) []string {
+	if source == ColorSourceColors && c.Colors != nil {
+		return c.Colors
+	}
+	return c.ColorIdentity
+}
+
+// Printings returns all sets each card name was printed in
+func (db CardDB) Printings() map[string][]string {
+	printings := map[string][]string{}
+	for _, card := range db {
+		printings[card.Name] = append(printings[card.Name], card.Set)
+	}
+	return printings
+}
+
+// Resolve looks up card names or ArenaIDs and returns the names of all found cards.
+// Names are matched case-insensitively, so every printing of a card is covered
+func (db CardDB) Resolve(entries []string) (map[string]bool, []string) {
+	names := map[string]string{}
+	for _, card := range db {
+		names[strings.ToLower(card.Name)] = card.Name
+	}
+
+	found := map[string]bool{}
+	var unresolved []string
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if card, ok := db[ArenaID(entry)]; ok {
+			found[card.Name] = true
+			continue
+		}
+
+		if name, ok := names[strings.ToLower(entry)]; ok {
+			found[name] = true
+			continue
+		}
+
+		unresolved = append(unresolved, entry)
+	}
+
+	return found, unresolved
 }
 
 // LoadCardDB gets the current card database from a file
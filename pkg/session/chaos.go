@@ -0,0 +1,81 @@
+package session
+
+import (
+	"math/rand"
+	"sort"
+)
+
+func init() {
+	RegisterMode(ModeChaos, chaos{})
+}
+
+// chaos is a booster draft where every pack comes from a randomly chosen set
+type chaos struct {
+	draft
+}
+
+// Start generates packs of random sets, seats the players and opens the first round
+func (chaos) Start(s *Session, cardDB CardDB) error {
+	return startDraft(s, cardDB, func(pool Collection, count int, rng *rand.Rand) ([]Pack, error) {
+		return generateChaosPacks(cardDB, pool, s.Options, count, rng)
+	})
+}
+
+// generateChaosPacks creates packs that each only contain cards of a single, randomly chosen set.
+// Sets that can't fill another pack are no longer chosen
+func generateChaosPacks(cardDB CardDB, pool Collection, opts Options, count int, rng *rand.Rand) ([]Pack, error) {
+	eligible := map[string]bool{}
+	for _, set := range opts.ChaosSets {
+		eligible[set] = true
+	}
+
+	// split the pool by set
+	bySet := map[string]Collection{}
+	for arenaID, count := range pool {
+		cardDetails, ok := cardDB[arenaID]
+		if !ok || len(eligible) > 0 && !eligible[cardDetails.Set] {
+			continue
+		}
+
+		if bySet[cardDetails.Set] == nil {
+			bySet[cardDetails.Set] = Collection{}
+		}
+		bySet[cardDetails.Set][arenaID] = count
+	}
+
+	sets := make([]string, 0, len(bySet))
+	for set := range bySet {
+		sets = append(sets, set)
+	}
+	sort.Strings(sets)
+
+	packs := make([]Pack, 0, count)
+	for len(packs) < count {
+		if len(sets) == 0 {
+			return nil, ErrNotEnoughCards
+		}
+
+		i := rng.Intn(len(sets))
+		set := sets[i]
+
+		generated, err := generatePacks(cardDB, bySet[set], opts.PackOptions, opts.Collation, 1, rng)
+		if err != nil {
+			sets = append(sets[:i], sets[i+1:]...)
+			continue
+		}
+
+		pack := generated[0]
+		pack.Set = set
+		packs = append(packs, pack)
+
+		// drawn cards are gone for the following packs
+		for _, arenaID := range pack.Cards {
+			bySet[set][arenaID]--
+			if bySet[set][arenaID] == 0 {
+				delete(bySet[set], arenaID)
+			}
+		}
+	}
+
+	return packs, nil
+}
@@ -0,0 +1,92 @@
+package session
+
+// DefaultMythicRate is the chance of a rare slot being upgraded to a mythic
+const DefaultMythicRate = 1.0 / 8
+
+// Collation describes the makeup of a booster
+type Collation struct {
+	// Slots defaults to one rare, three uncommons and commons for the rest of the pack
+	Slots []Slot `firestore:"slots" json:"slots"`
+	// MythicRate defaults to DefaultMythicRate
+	MythicRate *float64 `firestore:"mythic_rate" json:"mythic_rate"`
+	Duplicates bool     `firestore:"duplicates" json:"duplicates"`
+}
+
+// Slot is a number of cards of the same rarity in a booster
+type Slot struct {
+	Rarity string `firestore:"rarity" json:"rarity"`
+	Count  int    `firestore:"count" json:"count"`
+}
+
+// Validate checks the collation for unknown rarities and if it matches the pack options
+func (c Collation) Validate(opts PackOptions) error {
+	if c.MythicRate != nil && (*c.MythicRate < 0 || *c.MythicRate > 1) {
+		return ErrInvalidCollation
+	}
+
+	if len(c.Slots) == 0 {
+		return nil
+	}
+
+	size := 0
+	for _, slot := range c.Slots {
+		if slot.Count <= 0 || !isRarity(slot.Rarity) {
+			return ErrInvalidCollation
+		}
+		size += slot.Count
+	}
+
+	if size > maxPackSize {
+		return ErrInvalidCollation
+	}
+
+	// a pack size is optional with custom slots, but it has to match if given
+	if opts.Size != 0 && opts.Size != size {
+		return ErrInvalidCollation
+	}
+
+	return nil
+}
+
+func (c Collation) mythicRate() float64 {
+	if c.MythicRate == nil {
+		return DefaultMythicRate
+	}
+	return *c.MythicRate
+}
+
+// slots returns the rarity of each card in a pack
+func (c Collation) slots(opts PackOptions) []string {
+	if len(c.Slots) > 0 {
+		var slots []string
+		for _, slot := range c.Slots {
+			for i := 0; i < slot.Count; i++ {
+				slots = append(slots, slot.Rarity)
+			}
+		}
+		return slots
+	}
+
+	size := opts.size()
+	slots := make([]string, 0, size)
+	for i := 0; i < size; i++ {
+		switch {
+		case i == 0:
+			slots = append(slots, "rare")
+		case i <= 3:
+			slots = append(slots, "uncommon")
+		default:
+			slots = append(slots, "common")
+		}
+	}
+	return slots
+}
+
+func isRarity(rarity string) bool {
+	for _, r := range rarities {
+		if r == rarity {
+			return true
+		}
+	}
+	return false
+}
@@ -1,5 +1,10 @@
 package session
 
+import (
+	"math/rand"
+	"sort"
+)
+
 // Collection represents a card collection (id -> number of cards)
 type Collection map[ArenaID]byte
 
@@ -23,12 +28,61 @@ func (c Collection) Intersect(i Collection) {
 	}
 }
 
+// Threshold creates a collection of all cards owned by at least k of the given collections.
+// The count of each card is the highest count that at least k collections have. k == 1 is the union
+func Threshold(collections []Collection, k int) Collection {
+	counts := map[ArenaID][]byte{}
+	for _, collection := range collections {
+		for arenaID, count := range collection {
+			if count > 0 {
+				counts[arenaID] = append(counts[arenaID], count)
+			}
+		}
+	}
+
+	out := Collection{}
+	for arenaID, owned := range counts {
+		if len(owned) < k {
+			continue
+		}
+
+		// the k-th largest count
+		sort.Slice(owned, func(i, j int) bool { return owned[i] > owned[j] })
+		out[arenaID] = owned[k-1]
+	}
+	return out
+}
+
+// IntersectNames is Intersect by card name, so that owning any printing of a card counts
+func (c Collection) IntersectNames(cardDB CardDB, i Collection) {
+	owned := map[string]int{}
+	for arenaID, count := range i {
+		if cardDetails, ok := cardDB[arenaID]; ok {
+			owned[cardDetails.Name] += int(count)
+		}
+	}
+
+	for arenaID, count := range c {
+		cardDetails, ok := cardDB[arenaID]
+		if !ok || owned[cardDetails.Name] == 0 {
+			delete(c, arenaID)
+			continue
+		}
+
+		if int(count) > owned[cardDetails.Name] {
+			c[arenaID] = byte(owned[cardDetails.Name])
+		}
+	}
+}
+
 // MaxPerCard sets the card of the same name to at most the given number
 func (c Collection) MaxPerCard(cardDB CardDB, max byte) {
 	// create a map from card name to count (there are duplicates between sets, and we want to avoid that)
 	nameMap := map[string]ArenaID{}
 
-	for arenaID, count := range c {
+	// go through the cards in a stable order, so the kept printing doesn't change between runs
+	for _, arenaID := range c.ids() {
+		count := c[arenaID]
 		cardDetails, ok := cardDB[arenaID]
 		if !ok {
 			delete(c, arenaID)
@@ -68,9 +122,45 @@ func (c Collection) FilterRarities(cardDB CardDB, rarityOptions RarityOptions) {
 	}
 }
 
-// FilterSet only returns the cards that are part of the given set
-// TODO also allow the same cards from a different set
-func (c Collection) FilterSet(cardDB CardDB, set string) {
+// FilterSets only keeps the cards that are part of one of the given sets.
+// With anyPrinting, a card also stays if another printing of the same name is part of the sets
+func (c Collection) FilterSets(cardDB CardDB, sets []string, anyPrinting bool) {
+	keepSet := map[string]bool{}
+	for _, set := range sets {
+		keepSet[set] = true
+	}
+
+	var printings map[string][]string
+	if anyPrinting {
+		printings = cardDB.Printings()
+	}
+
+	for arenaID := range c {
+		cardDetails, ok := cardDB[arenaID]
+		if !ok {
+			delete(c, arenaID)
+			continue
+		}
+
+		if keepSet[cardDetails.Set] {
+			continue
+		}
+
+		keep := false
+		for _, set := range printings[cardDetails.Name] {
+			if keepSet[set] {
+				keep = true
+				break
+			}
+		}
+		if !keep {
+			delete(c, arenaID)
+		}
+	}
+}
+
+// FilterCMC removes all cards outside of the configured mana value range
+func (c Collection) FilterCMC(cardDB CardDB, cmcOptions CMCOptions) {
 	for arenaID := range c {
 		cardDetails, ok := cardDB[arenaID]
 		if !ok {
@@ -78,7 +168,57 @@ func (c Collection) FilterSet(cardDB CardDB, set string) {
 			continue
 		}
 
-		if cardDetails.Set != set {
+		if !cmcOptions.Lookup(cardDetails.CMC) {
+			delete(c, arenaID)
+		}
+	}
+}
+
+// FilterTypes removes all cards that don't have an included or have an excluded type
+func (c Collection) FilterTypes(cardDB CardDB, typeOptions TypeOptions) {
+	for arenaID := range c {
+		cardDetails, ok := cardDB[arenaID]
+		if !ok {
+			delete(c, arenaID)
+			continue
+		}
+
+		if !typeOptions.Lookup(cardDetails.Types()) {
+			delete(c, arenaID)
+		}
+	}
+}
+
+// FilterNames keeps (allow) or removes (!allow) all cards with the given names
+func (c Collection) FilterNames(cardDB CardDB, names map[string]bool, allow bool) {
+	for arenaID := range c {
+		cardDetails, ok := cardDB[arenaID]
+		if !ok {
+			delete(c, arenaID)
+			continue
+		}
+
+		if names[cardDetails.Name] != allow {
+			delete(c, arenaID)
+		}
+	}
+}
+
+// FilterQuery removes all cards not matching the query
+func (c Collection) FilterQuery(cardDB CardDB, query *Query) {
+	for arenaID := range c {
+		cardDetails, ok := cardDB[arenaID]
+		if !ok || !query.Match(cardDetails) {
+			delete(c, arenaID)
+		}
+	}
+}
+
+// FilterBooster removes all cards that can't be opened in boosters
+func (c Collection) FilterBooster(cardDB CardDB) {
+	for arenaID := range c {
+		cardDetails, ok := cardDB[arenaID]
+		if !ok || !cardDetails.InBooster {
 			delete(c, arenaID)
 		}
 	}
@@ -93,24 +233,79 @@ func (c Collection) FilterColors(cardDB CardDB, colors ColorOptions) {
 			continue
 		}
 
-		if len(cardDetails.ColorIdentity) == 0 {
-			if !colors.Colorless {
-				delete(c, arenaID)
+		if !colors.Matches(cardDetails.colors(colors.Source)) {
+			delete(c, arenaID)
+		}
+	}
+}
+
+// Sample randomly reduces the collection to the given number of cards.
+// If quotas are set, each rarity gets a share of the cards according to its weight
+func (c Collection) Sample(cardDB CardDB, size int, quotas RarityQuotas, rng *rand.Rand) {
+	s := newSupply(cardDB, c)
+	for arenaID := range c {
+		delete(c, arenaID)
+	}
+
+	// without quotas, all cards are drawn from a single pile
+	if quotas == (RarityQuotas{}) {
+		cards := s.cards()
+		rng.Shuffle(len(cards), func(i, j int) { cards[i], cards[j] = cards[j], cards[i] })
+		if size > len(cards) {
+			size = len(cards)
+		}
+		for _, arenaID := range cards[:size] {
+			c[arenaID]++
+		}
+		return
+	}
+
+	total := 0
+	for _, rarity := range rarities {
+		total += quotas.Lookup(rarity)
+	}
+
+	drawn := 0
+	for _, rarity := range rarities {
+		share := size * quotas.Lookup(rarity) / total
+		for i := 0; i < share; i++ {
+			arenaID, ok := s.draw(rng, rarity, nil)
+			if !ok {
+				break
 			}
-			continue
+			c[arenaID]++
+			drawn++
 		}
+	}
 
-		// all colors need to match for it to stay in the collection
-		for _, color := range cardDetails.ColorIdentity {
-			keep := colors.Lookup(color)
-			if !keep {
-				delete(c, arenaID)
+	// fill up rounding losses and depleted rarities with whatever is left, starting with the heaviest weights
+	order := append([]string{}, rarities...)
+	sort.SliceStable(order, func(i, j int) bool { return quotas.Lookup(order[i]) > quotas.Lookup(order[j]) })
+	for _, rarity := range order {
+		if quotas.Lookup(rarity) == 0 {
+			continue
+		}
+		for drawn < size {
+			arenaID, ok := s.draw(rng, rarity, nil)
+			if !ok {
 				break
 			}
+			c[arenaID]++
+			drawn++
 		}
 	}
 }
 
+// ids returns the IDs of all cards in the collection in a stable order
+func (c Collection) ids() []ArenaID {
+	ids := make([]ArenaID, 0, len(c))
+	for arenaID := range c {
+		ids = append(ids, arenaID)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
 // Copy creates a duplicate collection and returns it
 func (c Collection) Copy() Collection {
 	out := Collection{}
@@ -0,0 +1,24 @@
+package session
+
+func init() {
+	RegisterMode(ModeConstructed, constructed{})
+}
+
+// constructed gives every player the whole pool
+type constructed struct {
+	noActions
+}
+
+func (constructed) Validate(Options) error {
+	return nil
+}
+
+func (constructed) Start(s *Session, cardDB CardDB) error {
+	collection := s.pool(cardDB, s.rand())
+
+	//  write back for each player
+	for _, player := range s.Players {
+		player.SessionCollection = collection
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package session
+
+import (
+	"bufio"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// cubeLine matches "1 Llanowar Elves", "1x Llanowar Elves" and the MTGA export "1 Llanowar Elves (DOM) 168"
+var cubeLine = regexp.MustCompile(`^(?:(\d+)x?\s+)?(.+?)(?:\s+\(([A-Za-z0-9]+)\)(?:\s+(\S+))?)?$`)
+
+// sections of an MTGA export that aren't cards
+var cubeHeaders = map[string]bool{
+	"deck":       true,
+	"sideboard":  true,
+	"commander":  true,
+	"companion":  true,
+	"maybeboard": true,
+}
+
+// Cube is a saved card list that sessions can refer to by ID
+type Cube struct {
+	ID   string `firestore:"-" json:"id"`
+	Name string `firestore:"name" json:"name"`
+	List string `firestore:"list" json:"list"`
+}
+
+// Validate checks that the cube has a name and that all cards can be resolved
+func (c Cube) Validate(cardDB CardDB) error {
+	if strings.TrimSpace(c.Name) == "" {
+		return ErrInvalidCube
+	}
+
+	cube, unresolved := ParseCube(cardDB, c.List)
+	if len(unresolved) > 0 {
+		return &UnresolvedCardsError{Names: unresolved}
+	}
+	if len(cube) == 0 {
+		return ErrInvalidCube
+	}
+
+	return nil
+}
+
+// ParseCube resolves a plain text card list against the card database. It returns the cube
+// and all lines that could not be resolved. Lines with a set and collector number get that
+// printing, all other cards are resolved by name.
+func ParseCube(cardDB CardDB, list string) (Collection, []string) {
+	byName := map[string]ArenaID{}
+	byPrinting := map[string]ArenaID{}
+
+	// go through the card database in a stable order, so the same printing is picked every time
+	ids := make([]ArenaID, 0, len(cardDB))
+	for arenaID := range cardDB {
+		ids = append(ids, arenaID)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for _, arenaID := range ids {
+		card := cardDB[arenaID]
+		name := strings.ToLower(card.Name)
+		if _, ok := byName[name]; !ok {
+			byName[name] = arenaID
+		}
+		byPrinting[card.Set+"/"+card.CollectorNumber] = arenaID
+	}
+
+	cube := Collection{}
+	var unresolved []string
+
+	scanner := bufio.NewScanner(strings.NewReader(list))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") || cubeHeaders[strings.ToLower(line)] {
+			continue
+		}
+
+		match := cubeLine.FindStringSubmatch(line)
+		if match == nil {
+			unresolved = append(unresolved, line)
+			continue
+		}
+
+		count := 1
+		if match[1] != "" {
+			count, _ = strconv.Atoi(match[1])
+		}
+
+		arenaID, ok := byPrinting[strings.ToLower(match[3])+"/"+match[4]]
+		if !ok {
+			arenaID, ok = byName[strings.ToLower(match[2])]
+		}
+		if !ok {
+			unresolved = append(unresolved, line)
+			continue
+		}
+
+		if total := int(cube[arenaID]) + count; total < 255 {
+			cube[arenaID] = byte(total)
+		} else {
+			cube[arenaID] = 255
+		}
+	}
+
+	return cube, unresolved
+}
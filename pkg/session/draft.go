@@ -0,0 +1,432 @@
+package session
+
+import (
+	"math/rand"
+	"time"
+)
+
+func init() {
+	RegisterMode(ModeDraft, draft{})
+}
+
+// draft is a booster draft: packs are passed around the table after every pick
+type draft struct {
+	packValidation
+}
+
+// Draft contains the state of a booster draft
+type Draft struct {
+	Round    int          `firestore:"round" json:"round"`
+	Seats    []*DraftSeat `firestore:"seats" json:"seats"`
+	Finished bool         `firestore:"finished" json:"finished"`
+	Log      []PickLog    `firestore:"log" json:"-"`
+}
+
+// DraftSeat is a single seat at the draft table. Packs and picks are only visible to its player
+type DraftSeat struct {
+	PlayerID string    `firestore:"player_id" json:"player_id"`
+	Bot      bool      `firestore:"bot" json:"bot"`
+	Pick     int       `firestore:"pick" json:"pick"`
+	Queued   int       `firestore:"queued" json:"queued"`
+	Deadline time.Time `firestore:"deadline" json:"deadline"`
+	Queue    []Pack    `firestore:"queue" json:"-"`
+	Unopened []Pack    `firestore:"unopened" json:"-"`
+	Picks    []ArenaID `firestore:"picks" json:"-"`
+}
+
+// DraftView is the part of the draft a single player is allowed to see
+type DraftView struct {
+	Round    int       `json:"round"`
+	Pick     int       `json:"pick"`
+	Pack     *Pack     `json:"pack"`
+	Queued   int       `json:"queued"`
+	Picks    []ArenaID `json:"picks"`
+	Finished bool      `json:"finished"`
+
+	// Bots contains the pools of all bots, if they are exposed once the draft is finished
+	Bots map[string][]ArenaID `json:"bots,omitempty"`
+}
+
+func (d *Draft) copy() *Draft {
+	if d == nil {
+		return nil
+	}
+
+	out := *d
+	out.Seats = make([]*DraftSeat, len(d.Seats))
+	for i, seat := range d.Seats {
+		s := *seat
+		s.Queue = append([]Pack(nil), seat.Queue...)
+		s.Unopened = append([]Pack(nil), seat.Unopened...)
+		s.Picks = append([]ArenaID(nil), seat.Picks...)
+		out.Seats[i] = &s
+	}
+	out.Log = append([]PickLog(nil), d.Log...)
+	return &out
+}
+
+// Validate checks the pack options and the bot settings
+func (d draft) Validate(opts Options) error {
+	if err := d.packValidation.Validate(opts); err != nil {
+		return err
+	}
+
+	if _, ok := LookupBotStrategy(opts.BotStrategy); !ok {
+		return ErrUnknownBotStrategy
+	}
+	if opts.Bots > 0 && opts.BotStrategy == BotRating && len(opts.BotRatings) == 0 {
+		return ErrMissingRatings
+	}
+	return nil
+}
+
+// BotSeats returns the size of the largest table bots fill up
+func (draft) BotSeats() int {
+	return maxBotSeats
+}
+
+// SelfPaced marks drafts as playable asynchronously: every seat has its own queue of packs
+func (draft) SelfPaced() {}
+
+// Start generates all packs, seats the players and opens the first round
+func (draft) Start(s *Session, cardDB CardDB) error {
+	// all packs are drawn from the same supply, so nobody can end up with more copies than owned
+	return startDraft(s, cardDB, func(pool Collection, count int, rng *rand.Rand) ([]Pack, error) {
+		return generatePacks(cardDB, pool, s.Options.PackOptions, s.Options.Collation, count, rng)
+	})
+}
+
+// packGenerator creates the given number of packs out of the pool
+type packGenerator func(pool Collection, count int, rng *rand.Rand) ([]Pack, error)
+
+// startDraft generates all packs, seats the players and bots and opens the first round
+func startDraft(s *Session, cardDB CardDB, generate packGenerator) error {
+	rng := s.rand()
+	pool := s.pool(cardDB, rng)
+
+	playerIDs := s.playerIDs()
+	seats := len(playerIDs) + s.Options.Bots
+	if s.Options.Bots > 0 && seats > maxBotSeats {
+		return ErrTooManySeats
+	}
+	count := s.Options.PackOptions.count(DefaultDraftPackCount)
+
+	packs, err := generate(pool, count*seats, rng)
+	if err != nil {
+		return err
+	}
+
+	// spread the players evenly around the table, the bots fill the seats in between
+	d := &Draft{Seats: make([]*DraftSeat, seats)}
+	for i, playerID := range playerIDs {
+		d.Seats[i*seats/len(playerIDs)] = &DraftSeat{PlayerID: playerID}
+		s.Players[playerID].SessionCollection = Collection{}
+	}
+	bots := 0
+	for i, seat := range d.Seats {
+		if seat == nil {
+			bots++
+			seat = &DraftSeat{PlayerID: botID(bots), Bot: true}
+			d.Seats[i] = seat
+		}
+		seat.Unopened = packs[i*count : (i+1)*count]
+	}
+	now := time.Now()
+	d.open()
+	d.playBots(cardDB, s.Options, now)
+	d.updateDeadlines(s.Options.pickTime(), now)
+
+	s.Draft = d
+	return nil
+}
+
+// open gives every seat its next unopened pack
+func (d *Draft) open() {
+	for _, seat := range d.Seats {
+		seat.Pick = 0
+		if len(seat.Unopened) == 0 {
+			continue
+		}
+		seat.enqueue(seat.Unopened[0])
+		seat.Unopened = seat.Unopened[1:]
+	}
+}
+
+func (seat *DraftSeat) enqueue(pack Pack) {
+	seat.Queue = append(seat.Queue, pack)
+	seat.Queued = len(seat.Queue)
+}
+
+func (seat *DraftSeat) dequeue() Pack {
+	pack := seat.Queue[0]
+	seat.Queue = seat.Queue[1:]
+	seat.Queued = len(seat.Queue)
+	return pack
+}
+
+func (d *Draft) seat(playerID string) (int, *DraftSeat) {
+	for i, seat := range d.Seats {
+		if seat.PlayerID == playerID {
+			return i, seat
+		}
+	}
+	return -1, nil
+}
+
+// next returns the seat a pack is passed to: left in the first and third round, right in the second
+func (d *Draft) next(i int) *DraftSeat {
+	if d.Round%2 == 0 {
+		return d.Seats[(i+1)%len(d.Seats)]
+	}
+	return d.Seats[(i+len(d.Seats)-1)%len(d.Seats)]
+}
+
+// pick takes the given card out of the first pack in the seat's queue and passes the rest on
+func (d *Draft) pick(playerID string, arenaID ArenaID, now time.Time) error {
+	if d.Finished {
+		return ErrDraftFinished
+	}
+
+	i, seat := d.seat(playerID)
+	if seat == nil {
+		return ErrPlayerNotFound
+	}
+	if len(seat.Queue) == 0 {
+		return ErrNoPack
+	}
+
+	pack := seat.Queue[0]
+	index := -1
+	for j, card := range pack.Cards {
+		if card == arenaID {
+			index = j
+			break
+		}
+	}
+	if index < 0 {
+		return ErrInvalidPick
+	}
+
+	seat.dequeue()
+	d.Log = append(d.Log, PickLog{
+		PlayerID: playerID,
+		Pack:     d.Round,
+		Pick:     seat.Pick,
+		Offered:  pack.Cards,
+		Card:     arenaID,
+		Time:     now,
+	})
+	seat.Picks = append(seat.Picks, arenaID)
+	seat.Pick++
+	seat.Deadline = time.Time{}
+
+	// don't modify the cards in place, the slice may still be referenced elsewhere
+	rest := Pack{Cards: make([]ArenaID, 0, len(pack.Cards)-1)}
+	rest.Cards = append(rest.Cards, pack.Cards[:index]...)
+	rest.Cards = append(rest.Cards, pack.Cards[index+1:]...)
+	if len(rest.Cards) > 0 {
+		d.next(i).enqueue(rest)
+	}
+
+	// the round ends once every pack is empty
+	for _, seat := range d.Seats {
+		if len(seat.Queue) > 0 {
+			return nil
+		}
+	}
+
+	if len(d.Seats[0].Unopened) == 0 {
+		d.Finished = true
+		return nil
+	}
+
+	d.Round++
+	d.open()
+	return nil
+}
+
+// playBots lets the bots pick from all packs in their queues, until they wait for a player
+func (d *Draft) playBots(cardDB CardDB, opts Options, now time.Time) {
+	strategy, ok := LookupBotStrategy(opts.BotStrategy)
+	if !ok {
+		return
+	}
+
+	for picked := true; picked && !d.Finished; {
+		picked = false
+		for _, seat := range d.Seats {
+			if !seat.Bot || len(seat.Queue) == 0 {
+				continue
+			}
+
+			arenaID := strategy.Pick(cardDB, opts, seat.Picks, seat.Queue[0].Cards)
+			if d.pick(seat.PlayerID, arenaID, now) == nil {
+				picked = true
+			}
+		}
+	}
+
+	// nobody gets to see the bot pools, unless they are exposed for review
+	if d.Finished && !opts.BotPools {
+		for _, seat := range d.Seats {
+			if seat.Bot {
+				seat.Picks = nil
+			}
+		}
+
+		log := make([]PickLog, 0, len(d.Log))
+		for _, entry := range d.Log {
+			if _, seat := d.seat(entry.PlayerID); !seat.Bot {
+				log = append(log, entry)
+			}
+		}
+		d.Log = log
+	}
+}
+
+// Finished checks if all picks are made
+func (draft) Finished(s *Session) bool {
+	return s.Draft != nil && s.Draft.Finished
+}
+
+// HandleAction handles a pick of a player, the bots follow up on it
+func (draft) HandleAction(s *Session, cardDB CardDB, playerID string, action PlayerAction) error {
+	if action.Action != ActionPick {
+		return ErrInvalidAction
+	}
+
+	now := time.Now()
+	if err := s.Draft.pick(playerID, action.Card, now); err != nil {
+		return err
+	}
+
+	s.give(playerID, action.Card)
+	s.Draft.playBots(cardDB, s.Options, now)
+	s.Draft.updateDeadlines(s.Options.pickTime(), now)
+	return nil
+}
+
+// Expire picks for every player whose time is up, using the bot strategy of the session
+func (draft) Expire(s *Session, cardDB CardDB, now time.Time) bool {
+	d := s.Draft
+	strategy, ok := LookupBotStrategy(s.Options.BotStrategy)
+	if d == nil || !ok {
+		return false
+	}
+
+	changed := false
+	for _, seat := range d.Seats {
+		if d.Finished || seat.Deadline.IsZero() || now.Before(seat.Deadline) || len(seat.Queue) == 0 {
+			continue
+		}
+
+		arenaID := strategy.Pick(cardDB, s.Options, seat.Picks, seat.Queue[0].Cards)
+		if d.pick(seat.PlayerID, arenaID, now) != nil {
+			continue
+		}
+		s.give(seat.PlayerID, arenaID)
+		changed = true
+	}
+
+	if changed {
+		d.playBots(cardDB, s.Options, now)
+		d.updateDeadlines(s.Options.pickTime(), now)
+	}
+	return changed
+}
+
+// Leave turns the seat of the player into a bot seat, so the packs keep moving around the table
+func (draft) Leave(s *Session, cardDB CardDB, playerID string, now time.Time) {
+	d := s.Draft
+	if d == nil || d.Finished {
+		return
+	}
+
+	_, seat := d.seat(playerID)
+	if seat == nil {
+		return
+	}
+
+	seat.Bot = true
+	d.playBots(cardDB, s.Options, now)
+	d.updateDeadlines(s.Options.pickTime(), now)
+}
+
+// Deadlines returns until when the players have to pick from their current packs
+func (draft) Deadlines(s *Session) map[string]time.Time {
+	d := s.Draft
+	if d == nil || d.Finished {
+		return nil
+	}
+
+	deadlines := map[string]time.Time{}
+	for _, seat := range d.Seats {
+		if !seat.Deadline.IsZero() {
+			deadlines[seat.PlayerID] = seat.Deadline
+		}
+	}
+	return deadlines
+}
+
+// PickLog returns all picks so far
+func (draft) PickLog(s *Session) []PickLog {
+	if s.Draft == nil {
+		return nil
+	}
+	return s.Draft.Log
+}
+
+// updateDeadlines starts the clock for every player that has a new pack in front of them
+func (d *Draft) updateDeadlines(pickTime time.Duration, now time.Time) {
+	for _, seat := range d.Seats {
+		switch {
+		case d.Finished || seat.Bot || len(seat.Queue) == 0:
+			seat.Deadline = time.Time{}
+		case seat.Deadline.IsZero():
+			seat.Deadline = deadline(pickTime, now)
+		}
+	}
+}
+
+// View returns the draft as seen by the given player
+func (draft) View(s *Session, playerID string) interface{} {
+	if view := s.DraftView(playerID); view != nil {
+		return view
+	}
+	return nil
+}
+
+// DraftView returns the draft as seen by the given player (nil == no draft or player not seated)
+func (s *Session) DraftView(playerID string) *DraftView {
+	if s.Draft == nil {
+		return nil
+	}
+
+	_, seat := s.Draft.seat(playerID)
+	if seat == nil {
+		return nil
+	}
+
+	view := &DraftView{
+		Round:    s.Draft.Round,
+		Pick:     seat.Pick,
+		Queued:   seat.Queued,
+		Picks:    seat.Picks,
+		Finished: s.Draft.Finished,
+	}
+	if len(seat.Queue) > 0 {
+		view.Pack = &seat.Queue[0]
+	}
+	if s.Draft.Finished && s.Options.BotPools {
+		for _, other := range s.Draft.Seats {
+			if other.Bot {
+				if view.Bots == nil {
+					view.Bots = map[string][]ArenaID{}
+				}
+				view.Bots[other.PlayerID] = other.Picks
+			}
+		}
+	}
+
+	return view
+}
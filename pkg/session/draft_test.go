@@ -0,0 +1,58 @@
+package session
+
+import "testing"
+
+func TestDraftPlaysToFinish(t *testing.T) {
+	tests := []struct {
+		players []string
+		bots    int
+	}{
+		{[]string{"a", "b"}, 0},
+		{[]string{"a", "b", "c"}, 0},
+		{[]string{"a", "b"}, 1},
+		{[]string{"a"}, 3},
+	}
+
+	for _, test := range tests {
+		cardDB, collection := testCards(60)
+		opts := Options{Mode: ModeDraft, Seed: 1, Bots: test.bots, PackOptions: PackOptions{Count: 2, Size: 3}}
+		s := startTestSession(t, cardDB, collection, opts, test.players...)
+
+		// every player picks the first card of their pack in turn, the bots follow up on their own
+		for picks := 0; !s.Draft.Finished; picks++ {
+			if picks > 2*3*len(test.players) {
+				t.Fatalf("%d players, %d bots: draft did not finish", len(test.players), test.bots)
+			}
+
+			var action PlayerAction
+			var playerID string
+			for _, seat := range s.Draft.Seats {
+				if !seat.Bot && len(seat.Queue) > 0 {
+					playerID, action = seat.PlayerID, PlayerAction{Action: ActionPick, Card: seat.Queue[0].Cards[0]}
+					break
+				}
+			}
+			if playerID == "" {
+				t.Fatalf("%d players, %d bots: no pack to pick from", len(test.players), test.bots)
+			}
+
+			if err := s.HandleAction(cardDB, playerID, action); err != nil {
+				t.Fatalf("%d players, %d bots: %v", len(test.players), test.bots, err)
+			}
+		}
+
+		if s.Draft.Round != 1 {
+			t.Errorf("%d players, %d bots: finished in round %d", len(test.players), test.bots, s.Draft.Round)
+		}
+		for _, playerID := range test.players {
+			if got := len(s.Players[playerID].SessionCollection); got != 2*3 {
+				t.Errorf("%d players, %d bots: %s drafted %d cards", len(test.players), test.bots, playerID, got)
+			}
+		}
+
+		err := s.HandleAction(cardDB, test.players[0], PlayerAction{Action: ActionPick, Card: "00000"})
+		if err != ErrDraftFinished {
+			t.Errorf("%d players, %d bots: expected %v after the end, got %v", len(test.players), test.bots, ErrDraftFinished, err)
+		}
+	}
+}
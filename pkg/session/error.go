@@ -0,0 +1,57 @@
+package session
+
+import "strings"
+
+// Errors
+const (
+	ErrUnknownMode         Error = "unknown game mode"
+	ErrInvalidPackOptions  Error = "invalid pack options"
+	ErrInvalidCollation    Error = "invalid booster collation"
+	ErrInvalidCMCRange     Error = "invalid mana value range"
+	ErrInvalidColorOptions Error = "invalid color options"
+	ErrNoTypeData          Error = "the card database has no type lines, type filters are not available"
+	ErrNoColorData         Error = "the card database has no casting colors, only the color identity is available"
+	ErrInvalidPooling      Error = "invalid pooling mode"
+	ErrInvalidPoolSize     Error = "invalid pool size"
+	ErrInvalidPickTime     Error = "invalid pick time"
+	ErrInvalidBots         Error = "invalid number of bots"
+	ErrBotsNotSupported    Error = "bots are not supported in this game mode"
+	ErrTooManySeats        Error = "too many players and bots for one table"
+	ErrUnknownBotStrategy  Error = "unknown bot strategy"
+	ErrMissingRatings      Error = "rating bots need card ratings"
+	ErrAsyncNotSupported   Error = "asynchronous sessions are not supported in this game mode"
+	ErrRejoinNotAllowed    Error = "only asynchronous sessions can be rejoined"
+	ErrInvalidToken        Error = "invalid player token"
+	ErrInvalidCube         Error = "cube needs a name and at least one card"
+	ErrNotEnoughCards      Error = "not enough cards to generate packs"
+	ErrNotStarted          Error = "session not started"
+	ErrPlayerNotFound      Error = "player not found"
+	ErrInvalidAction       Error = "invalid action"
+	ErrInvalidPick         Error = "card is not part of the current pack"
+	ErrNoPack              Error = "no pack to pick from"
+	ErrDraftFinished       Error = "draft already finished"
+	ErrDraftNotFinished    Error = "draft not finished yet"
+	ErrNoPickLog           Error = "picks are not logged in this mode"
+	ErrTwoPlayersOnly      Error = "this mode needs exactly two players"
+	ErrTeamsOfTwo          Error = "every team needs exactly two players"
+	ErrNotYourTurn         Error = "not your turn"
+	ErrNotLooked           Error = "look at the pile first"
+	ErrMustTake            Error = "not enough cards on the stack, the last pile has to be taken"
+	ErrEmptyLine           Error = "row or column is already empty"
+)
+
+// Error describes session-related errors
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+// UnresolvedCardsError is returned if card names or IDs could not be found in the card database
+type UnresolvedCardsError struct {
+	Names []string
+}
+
+func (e *UnresolvedCardsError) Error() string {
+	return "unknown cards: " + strings.Join(e.Names, ", ")
+}
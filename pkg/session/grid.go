@@ -0,0 +1,262 @@
+package session
+
+import "time"
+
+// DefaultGridCount is the number of grids in a grid draft without a configured pack count
+const DefaultGridCount = 18
+
+const gridSize = 3
+
+func init() {
+	RegisterMode(ModeGrid, grid{})
+}
+
+// grid is a draft of face-up 3x3 grids: players take turns picking a row or a column of each grid
+type grid struct{}
+
+// Grid contains the state of a grid draft. The current grid is public, the upcoming ones are hidden
+type Grid struct {
+	Players  []string  `firestore:"players" json:"players"`
+	Round    int       `firestore:"round" json:"round"`
+	Rounds   int       `firestore:"rounds" json:"rounds"`
+	Turn     string    `firestore:"turn" json:"turn"`
+	Picks    int       `firestore:"picks" json:"picks"`
+	Cells    []ArenaID `firestore:"cells" json:"cells"`
+	Deadline time.Time `firestore:"deadline" json:"deadline"`
+	Finished bool      `firestore:"finished" json:"finished"`
+
+	Grids []Pack    `firestore:"grids" json:"-"`
+	Log   []PickLog `firestore:"log" json:"-"`
+}
+
+func (g *Grid) copy() *Grid {
+	if g == nil {
+		return nil
+	}
+
+	out := *g
+	out.Cells = append([]ArenaID(nil), g.Cells...)
+	out.Grids = append([]Pack(nil), g.Grids...)
+	out.Log = append([]PickLog(nil), g.Log...)
+	return &out
+}
+
+// Validate checks the number of grids, taken from the pack count
+func (grid) Validate(opts Options) error {
+	return opts.PackOptions.Validate()
+}
+
+// Start lays out all grids from the pool
+func (grid) Start(s *Session, cardDB CardDB) error {
+	rng := s.rand()
+	pool := s.pool(cardDB, rng)
+
+	cards := newSupply(cardDB, pool).cards()
+	rng.Shuffle(len(cards), func(i, j int) { cards[i], cards[j] = cards[j], cards[i] })
+
+	g := &Grid{Players: s.playerIDs()}
+	count := s.Options.PackOptions.count(DefaultGridCount)
+	for i := 0; i < count && len(cards) >= gridSize*gridSize; i++ {
+		g.Grids = append(g.Grids, Pack{Cards: cards[:gridSize*gridSize]})
+		cards = cards[gridSize*gridSize:]
+	}
+	if len(g.Grids) == 0 {
+		return ErrNotEnoughCards
+	}
+
+	g.Rounds = len(g.Grids)
+	g.Round = -1
+	g.next()
+	g.Deadline = deadline(s.Options.pickTime(), time.Now())
+
+	for _, player := range s.Players {
+		player.SessionCollection = Collection{}
+	}
+
+	s.Grid = g
+	return nil
+}
+
+// Finished checks if all picks are made
+func (grid) Finished(s *Session) bool {
+	return s.Grid != nil && s.Grid.Finished
+}
+
+// PickLog returns all rows and columns taken so far
+func (grid) PickLog(s *Session) []PickLog {
+	if s.Grid == nil {
+		return nil
+	}
+	return s.Grid.Log
+}
+
+// HandleAction takes a row or a column of the current grid
+func (grid) HandleAction(s *Session, cardDB CardDB, playerID string, action PlayerAction) error {
+	g := s.Grid
+	if g.Finished {
+		return ErrDraftFinished
+	}
+	if g.Turn != playerID {
+		return ErrNotYourTurn
+	}
+
+	now := time.Now()
+	if err := g.take(s, action, now); err != nil {
+		return err
+	}
+
+	g.standIn(s, now)
+	return nil
+}
+
+// Expire takes the fullest line of the grid for the active player once their time is up
+func (grid) Expire(s *Session, cardDB CardDB, now time.Time) bool {
+	g := s.Grid
+	if g == nil || g.Finished || g.Deadline.IsZero() || now.Before(g.Deadline) {
+		return false
+	}
+
+	if g.take(s, g.fullest(), now) != nil {
+		return false
+	}
+
+	g.standIn(s, now)
+	return true
+}
+
+// Leave takes lines for the player whenever it is their turn
+func (grid) Leave(s *Session, cardDB CardDB, playerID string, now time.Time) {
+	if s.Grid != nil {
+		s.Grid.standIn(s, now)
+	}
+}
+
+// Deadlines returns when the active player runs out of time
+func (grid) Deadlines(s *Session) map[string]time.Time {
+	g := s.Grid
+	if g == nil || g.Finished || g.Deadline.IsZero() {
+		return nil
+	}
+	return map[string]time.Time{g.Turn: g.Deadline}
+}
+
+// fullest returns the line with the most cards left. Ties go to the first row, then the first column
+func (g *Grid) fullest() PlayerAction {
+	best, most := PlayerAction{}, 0
+	for _, action := range []string{ActionRow, ActionColumn} {
+		for index := 0; index < gridSize; index++ {
+			count := 0
+			for _, cell := range g.line(action, index) {
+				if g.Cells[cell] != "" {
+					count++
+				}
+			}
+			if count > most {
+				best, most = PlayerAction{Action: action, Index: index}, count
+			}
+		}
+	}
+	return best
+}
+
+// standIn takes the fullest line for players who left, until it is the turn of a player still there
+func (g *Grid) standIn(s *Session, now time.Time) {
+	for !g.Finished {
+		if _, ok := s.Players[g.Turn]; ok {
+			return
+		}
+		if g.take(s, g.fullest(), now) != nil {
+			return
+		}
+	}
+}
+
+// line returns the cells of the given row or column (nil == invalid)
+func (g *Grid) line(action string, index int) []int {
+	if index < 0 || index >= gridSize {
+		return nil
+	}
+
+	var cells []int
+	for i := 0; i < gridSize; i++ {
+		switch action {
+		case ActionRow:
+			cells = append(cells, index*gridSize+i)
+		case ActionColumn:
+			cells = append(cells, i*gridSize+index)
+		default:
+			return nil
+		}
+	}
+	return cells
+}
+
+// take gives a row or a column of the current grid to the active player and restarts the clock
+func (g *Grid) take(s *Session, action PlayerAction, now time.Time) error {
+	playerID := g.Turn
+	cells := g.line(action.Action, action.Index)
+	if cells == nil {
+		return ErrInvalidAction
+	}
+
+	var picked []ArenaID
+	for _, cell := range cells {
+		if g.Cells[cell] != "" {
+			picked = append(picked, g.Cells[cell])
+		}
+	}
+	if len(picked) == 0 {
+		return ErrEmptyLine
+	}
+
+	for _, cell := range cells {
+		g.Cells[cell] = ""
+	}
+	g.Log = append(g.Log, PickLog{
+		PlayerID: playerID,
+		Pack:     g.Round,
+		Pick:     g.Picks,
+		Offered:  picked,
+		Cards:    picked,
+		Time:     now,
+	})
+	s.give(playerID, picked...)
+
+	// every player picks once per grid, the rest of the grid is discarded
+	g.Picks++
+	if g.Picks >= len(g.Players) || g.empty() {
+		g.next()
+	} else {
+		g.Turn = g.Players[(g.Round+g.Picks)%len(g.Players)]
+	}
+
+	g.Deadline = time.Time{}
+	if !g.Finished {
+		g.Deadline = deadline(s.Options.pickTime(), now)
+	}
+	return nil
+}
+
+// next lays out the next grid; the starting player rotates every round
+func (g *Grid) next() {
+	g.Round++
+	g.Picks = 0
+	if g.Round >= len(g.Grids) {
+		g.Cells = nil
+		g.Turn = ""
+		g.Finished = true
+		return
+	}
+
+	g.Cells = append([]ArenaID{}, g.Grids[g.Round].Cards...)
+	g.Turn = g.Players[g.Round%len(g.Players)]
+}
+
+func (g *Grid) empty() bool {
+	for _, cell := range g.Cells {
+		if cell != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,59 @@
+package session
+
+import "testing"
+
+func TestGridPlaysToFinish(t *testing.T) {
+	tests := []struct {
+		players []string
+		action  string
+		// cards per player
+		cards map[string]int
+	}{
+		{[]string{"a", "b"}, ActionRow, map[string]int{"a": 9, "b": 9}},
+		{[]string{"a", "b", "c"}, ActionColumn, map[string]int{"a": 9, "b": 9, "c": 9}},
+		// the grid is empty after three lines, so the fourth player to pick in a round gets nothing
+		{[]string{"a", "b", "c", "d"}, ActionRow, map[string]int{"a": 6, "b": 6, "c": 9, "d": 6}},
+	}
+
+	for _, test := range tests {
+		cardDB, collection := testCards(60)
+		opts := Options{Mode: ModeGrid, Seed: 1, PackOptions: PackOptions{Count: 3}}
+		s := startTestSession(t, cardDB, collection, opts, test.players...)
+		g := s.Grid
+
+		for picks := 0; !g.Finished; picks++ {
+			if picks > 3*len(test.players) {
+				t.Fatalf("%d players: grid draft did not finish", len(test.players))
+			}
+
+			other := test.players[0]
+			if other == g.Turn {
+				other = test.players[1]
+			}
+			if err := s.HandleAction(cardDB, other, PlayerAction{Action: test.action}); err != ErrNotYourTurn {
+				t.Fatalf("%d players: expected %v, got %v", len(test.players), ErrNotYourTurn, err)
+			}
+
+			// take the first line with cards left, after trying the empty ones
+			index := 0
+			for ; ; index++ {
+				err := s.HandleAction(cardDB, g.Turn, PlayerAction{Action: test.action, Index: index})
+				if err == nil {
+					break
+				}
+				if err != ErrEmptyLine {
+					t.Fatalf("%d players: %v", len(test.players), err)
+				}
+			}
+		}
+
+		if g.Round != 3 || g.Turn != "" || g.Cells != nil {
+			t.Errorf("%d players: finished in round %d, turn %q, cells %v", len(test.players), g.Round, g.Turn, g.Cells)
+		}
+		for playerID, cards := range test.cards {
+			if got := len(s.Players[playerID].SessionCollection); got != cards {
+				t.Errorf("%d players: %s drafted %d cards, expected %d", len(test.players), playerID, got, cards)
+			}
+		}
+	}
+}
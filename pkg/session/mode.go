@@ -0,0 +1,113 @@
+package session
+
+import "time"
+
+// Game modes
+const (
+	ModeConstructed    = "constructed"
+	ModeSealed         = "sealed"
+	ModeDraft          = "draft"
+	ModeSplit          = "split"
+	ModeWinston        = "winston"
+	ModeGrid           = "grid"
+	ModeRochester      = "rochester"
+	ModeChaos          = "chaos"
+	ModeTeamSealed     = "team_sealed"
+	ModeTwoHeadedGiant = "2hg"
+)
+
+// GameMode is a format that can be played in a session
+type GameMode interface {
+	// Validate checks the mode-specific options on session creation
+	Validate(Options) error
+	// Start builds the pools of all players and sets up any in-game state
+	Start(*Session, CardDB) error
+	// HandleAction applies an in-game action of a player
+	HandleAction(*Session, CardDB, string, PlayerAction) error
+}
+
+// viewer is implemented by game modes with hidden information
+type viewer interface {
+	// View returns what the given player is allowed to see (nil == nothing)
+	View(*Session, string) interface{}
+}
+
+// finisher is implemented by game modes that are still played once the session has started
+type finisher interface {
+	// Finished checks if the game is over
+	Finished(*Session) bool
+}
+
+// timed is implemented by game modes with pick time limits
+type timed interface {
+	// Expire makes the moves of all players whose time is up, returns if anything changed
+	Expire(*Session, CardDB, time.Time) bool
+	// Deadlines returns until when each player has to make their move
+	Deadlines(*Session) map[string]time.Time
+}
+
+// logged is implemented by game modes that record every pick
+type logged interface {
+	// PickLog returns all picks so far
+	PickLog(*Session) []PickLog
+}
+
+// leaver is implemented by game modes that go on without players who leave during the game
+type leaver interface {
+	// Leave takes over the moves of the given player, who is no longer part of the session
+	Leave(*Session, CardDB, string, time.Time)
+}
+
+// botSeater is implemented by game modes with seats that bots can fill
+type botSeater interface {
+	// BotSeats returns the size of the largest table bots fill up
+	BotSeats() int
+}
+
+// selfPaced is implemented by game modes that everybody can play at their own pace, leaving and returning later
+type selfPaced interface {
+	// SelfPaced marks the mode as playable asynchronously
+	SelfPaced()
+}
+
+// deadline returns when a move started now has to be made (zero == no time limit)
+func deadline(pickTime time.Duration, now time.Time) time.Time {
+	if pickTime <= 0 {
+		return time.Time{}
+	}
+	return now.Add(pickTime)
+}
+
+var modes = map[string]GameMode{}
+
+// RegisterMode makes a game mode available under the given name
+func RegisterMode(name string, mode GameMode) {
+	modes[name] = mode
+}
+
+// LookupMode returns the game mode with the given name. No name is constructed
+func LookupMode(name string) (GameMode, bool) {
+	if name == "" {
+		name = ModeConstructed
+	}
+
+	mode, ok := modes[name]
+	return mode, ok
+}
+
+// noActions can be embedded by game modes that are over once the pools are built
+type noActions struct{}
+
+func (noActions) HandleAction(*Session, CardDB, string, PlayerAction) error {
+	return ErrInvalidAction
+}
+
+// packValidation can be embedded by game modes that generate boosters
+type packValidation struct{}
+
+func (packValidation) Validate(opts Options) error {
+	if err := opts.PackOptions.Validate(); err != nil {
+		return err
+	}
+	return opts.Collation.Validate(opts.PackOptions)
+}
@@ -0,0 +1,30 @@
+package session
+
+import "sort"
+
+// autoPick picks the card of the highest rarity. Ties go to the lowest ArenaID, so the result is deterministic
+func autoPick(cardDB CardDB, cards []ArenaID) ArenaID {
+	sorted := append([]ArenaID{}, cards...)
+	sort.Slice(sorted, func(i, j int) bool {
+		ri, rj := rarityRank(cardDB[sorted[i]].Rarity), rarityRank(cardDB[sorted[j]].Rarity)
+		if ri != rj {
+			return ri > rj
+		}
+		return sorted[i] < sorted[j]
+	})
+
+	if len(sorted) == 0 {
+		return ""
+	}
+	return sorted[0]
+}
+
+// rarityRank orders the rarities from common (0) to mythic (3)
+func rarityRank(rarity string) int {
+	for i, r := range rarities {
+		if r == rarity {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,93 @@
+package session
+
+import "time"
+
+// PickLog is a single recorded pick. Packs and picks are counted from 0, like in the draft views.
+// Modes that take several cards at once list them in Cards instead of Card:
+// Winston offers a pile (the stack counts as the pile after the last one), grid a row or column
+type PickLog struct {
+	PlayerID string    `firestore:"player_id" json:"player_id"`
+	Pack     int       `firestore:"pack" json:"pack"`
+	Pick     int       `firestore:"pick" json:"pick"`
+	Offered  []ArenaID `firestore:"offered" json:"offered"`
+	Card     ArenaID   `firestore:"card" json:"card,omitempty"`
+	Cards    []ArenaID `firestore:"cards" json:"cards,omitempty"`
+	Time     time.Time `firestore:"time" json:"time"`
+}
+
+// PickLog returns every pick of the session, once the draft is finished
+func (s *Session) PickLog() ([]PickLog, error) {
+	log, err := s.pickLog()
+	if err != nil {
+		return nil, err
+	}
+	if !s.finished() {
+		return nil, ErrDraftNotFinished
+	}
+	return log, nil
+}
+
+// PlayerPicks returns the picks of the given player so far
+func (s *Session) PlayerPicks(playerID string) ([]PickLog, error) {
+	if _, ok := s.Players[playerID]; !ok {
+		return nil, ErrPlayerNotFound
+	}
+
+	log, err := s.pickLog()
+	if err != nil {
+		return nil, err
+	}
+
+	picks := []PickLog{}
+	for _, entry := range log {
+		if entry.PlayerID == playerID {
+			picks = append(picks, entry)
+		}
+	}
+	return picks, nil
+}
+
+// PlayerCollection returns the session collection of the given player. It holds the picks during the game,
+// so only the player may see it until the game is over
+func (s *Session) PlayerCollection(playerID string, token string) (Collection, error) {
+	player, ok := s.Players[playerID]
+	if !ok {
+		return nil, ErrPlayerNotFound
+	}
+
+	if !s.finished() {
+		if err := s.Authenticate(playerID, token); err != nil {
+			return nil, err
+		}
+	}
+	return player.SessionCollection, nil
+}
+
+func (s *Session) pickLog() ([]PickLog, error) {
+	if !s.Started {
+		return nil, ErrNotStarted
+	}
+
+	mode, ok := LookupMode(s.Options.Mode)
+	if !ok {
+		return nil, ErrUnknownMode
+	}
+
+	l, ok := mode.(logged)
+	if !ok {
+		return nil, ErrNoPickLog
+	}
+
+	return l.PickLog(s), nil
+}
+
+// picks counts the picks of the given player in the log
+func picks(log []PickLog, playerID string) int {
+	count := 0
+	for _, entry := range log {
+		if entry.PlayerID == playerID {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,124 @@
+package session
+
+import "testing"
+
+func TestPickLog(t *testing.T) {
+	cardDB, collection := testCards(60)
+	opts := Options{Seed: 1, PackOptions: PackOptions{Count: 1, Size: 4}}
+
+	// every mode takes the first thing offered until the game is over
+	moves := map[string]func(s *Session) (string, PlayerAction){
+		ModeDraft: func(s *Session) (string, PlayerAction) {
+			for _, seat := range s.Draft.Seats {
+				if len(seat.Queue) > 0 {
+					return seat.PlayerID, PlayerAction{Action: ActionPick, Card: seat.Queue[0].Cards[0]}
+				}
+			}
+			return "", PlayerAction{}
+		},
+		ModeWinston: func(s *Session) (string, PlayerAction) {
+			if !s.Winston.Looked {
+				return s.Winston.Turn, PlayerAction{Action: ActionLook}
+			}
+			return s.Winston.Turn, PlayerAction{Action: ActionTake}
+		},
+		ModeGrid: func(s *Session) (string, PlayerAction) {
+			for row := 0; row < gridSize; row++ {
+				for _, cell := range s.Grid.line(ActionRow, row) {
+					if s.Grid.Cells[cell] != "" {
+						return s.Grid.Turn, PlayerAction{Action: ActionRow, Index: row}
+					}
+				}
+			}
+			return "", PlayerAction{}
+		},
+	}
+
+	for mode, move := range moves {
+		opts.Mode = mode
+		s := startTestSession(t, cardDB, collection, opts, "a", "b")
+
+		if _, err := s.PickLog(); err != ErrDraftNotFinished {
+			t.Errorf("%s: expected %v before the end, got %v", mode, ErrDraftNotFinished, err)
+		}
+
+		for i := 0; !s.finished(); i++ {
+			if i > 1000 {
+				t.Fatalf("%s: game did not finish", mode)
+			}
+			playerID, action := move(s)
+			if err := s.HandleAction(cardDB, playerID, action); err != nil {
+				t.Fatalf("%s: %v", mode, err)
+			}
+		}
+
+		log, err := s.PickLog()
+		if err != nil {
+			t.Fatalf("%s: %v", mode, err)
+		}
+
+		logged := 0
+		for _, entry := range log {
+			logged += len(entry.Cards)
+			if entry.Card != "" {
+				logged++
+			}
+			if len(entry.Offered) == 0 {
+				t.Errorf("%s: nothing offered in %+v", mode, entry)
+			}
+		}
+		if logged != countCards(s) {
+			t.Errorf("%s: %d cards logged, %d drafted", mode, logged, countCards(s))
+		}
+
+		picks, err := s.PlayerPicks("a")
+		if err != nil {
+			t.Fatalf("%s: %v", mode, err)
+		}
+		for i, entry := range picks {
+			if entry.PlayerID != "a" {
+				t.Errorf("%s: pick of another player %+v", mode, entry)
+			}
+			// grids count the picks per grid
+			if mode != ModeGrid && entry.Pick != i {
+				t.Errorf("%s: pick %d numbered %d", mode, i, entry.Pick)
+			}
+		}
+	}
+
+	opts.Mode = ModeSealed
+	s := startTestSession(t, cardDB, collection, opts, "a", "b")
+	if _, err := s.PickLog(); err != ErrNoPickLog {
+		t.Errorf("sealed: expected %v, got %v", ErrNoPickLog, err)
+	}
+}
+
+func TestPlayerCollection(t *testing.T) {
+	cardDB, collection := testCards(60)
+	opts := Options{Mode: ModeDraft, Seed: 1, PackOptions: PackOptions{Count: 1, Size: 4}}
+	s := startTestSession(t, cardDB, collection, opts, "a", "b")
+	s.Players["a"].Token = "secret"
+
+	tests := []struct {
+		playerID string
+		token    string
+		err      error
+	}{
+		{"a", "secret", nil},
+		{"a", "", ErrInvalidToken},
+		{"b", "secret", ErrInvalidToken},
+		{"c", "secret", ErrPlayerNotFound},
+	}
+	for _, test := range tests {
+		if _, err := s.PlayerCollection(test.playerID, test.token); err != test.err {
+			t.Errorf("%s with %q: expected %v, got %v", test.playerID, test.token, test.err, err)
+		}
+	}
+
+	// sealed pools are complete from the start, nothing to hide
+	opts.Mode = ModeSealed
+	s = startTestSession(t, cardDB, collection, opts, "a", "b")
+	if _, err := s.PlayerCollection("b", ""); err != nil {
+		t.Errorf("sealed: %v", err)
+	}
+}
@@ -0,0 +1,534 @@
+package session
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Query is a parsed card filter in a Scryfall-like syntax, e.g. "r>=uncommon c<=ug cmc<=5 -t:land set:war,rna".
+//
+// Terms are combined with AND unless separated by "or"; "-" negates a term or a group in parentheses.
+// Supported keys are r/rarity, c/color, id/identity, cmc/mv, t/type, s/e/set, n/name and in:booster.
+// Words without a key match card names.
+type Query struct {
+	expr queryExpr
+	// types is set if the query filters on type lines
+	types bool
+	// colors is set if the query filters on casting colors
+	colors bool
+}
+
+// QueryError describes a syntax error in a query. Position is the byte offset of the error
+type QueryError struct {
+	Position int
+	Message  string
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("invalid query at position %d: %s", e.Position, e.Message)
+}
+
+// ParseQuery parses the given query string. An empty query matches every card
+func ParseQuery(query string) (*Query, error) {
+	tokens, err := lexQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &queryParser{tokens: tokens, end: len(query)}
+	if len(tokens) == 0 {
+		return &Query{}, nil
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok != nil {
+		return nil, &QueryError{Position: tok.pos, Message: "unexpected " + tok.describe()}
+	}
+
+	q := &Query{expr: expr}
+	for _, tok := range tokens {
+		if match := termPattern.FindStringSubmatch(tok.text); match != nil && !tok.literal {
+			key := strings.ToLower(match[2])
+			q.types = q.types || key == "t" || key == "type"
+			q.colors = q.colors || key == "c" || key == "color"
+		}
+	}
+	return q, nil
+}
+
+// UsesTypes checks if the query filters on type lines
+func (q *Query) UsesTypes() bool {
+	return q != nil && q.types
+}
+
+// UsesColors checks if the query filters on casting colors
+func (q *Query) UsesColors() bool {
+	return q != nil && q.colors
+}
+
+// Match checks if the given card matches the query
+func (q *Query) Match(card CardData) bool {
+	if q == nil || q.expr == nil {
+		return true
+	}
+	return q.expr.match(card)
+}
+
+type queryExpr interface {
+	match(CardData) bool
+}
+
+type andExpr []queryExpr
+
+func (a andExpr) match(card CardData) bool {
+	for _, expr := range a {
+		if !expr.match(card) {
+			return false
+		}
+	}
+	return true
+}
+
+type orExpr []queryExpr
+
+func (o orExpr) match(card CardData) bool {
+	for _, expr := range o {
+		if expr.match(card) {
+			return true
+		}
+	}
+	return false
+}
+
+type notExpr struct {
+	expr queryExpr
+}
+
+func (n notExpr) match(card CardData) bool {
+	return !n.expr.match(card)
+}
+
+type termExpr func(CardData) bool
+
+func (t termExpr) match(card CardData) bool {
+	return t(card)
+}
+
+// tokens
+
+type queryToken struct {
+	pos  int
+	text string
+	// quoted tokens are never keywords, literal tokens (starting with a quote) never key:value terms
+	quoted  bool
+	literal bool
+}
+
+func (t *queryToken) describe() string {
+	return fmt.Sprintf("%q", t.text)
+}
+
+func (t *queryToken) is(text string) bool {
+	return t != nil && !t.quoted && strings.EqualFold(t.text, text)
+}
+
+// lexQuery splits the query into words and parentheses. Quoted parts may contain spaces
+func lexQuery(query string) ([]*queryToken, error) {
+	var tokens []*queryToken
+	for i := 0; i < len(query); {
+		switch c := query[i]; {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, &queryToken{pos: i, text: string(c)})
+			i++
+		default:
+			tok := &queryToken{pos: i, literal: c == '"'}
+			var text strings.Builder
+			for i < len(query) && !strings.ContainsRune(" \t\n()", rune(query[i])) {
+				if query[i] != '"' {
+					text.WriteByte(query[i])
+					i++
+					continue
+				}
+
+				end := strings.IndexByte(query[i+1:], '"')
+				if end < 0 {
+					return nil, &QueryError{Position: i, Message: "unterminated quote"}
+				}
+				text.WriteString(query[i+1 : i+1+end])
+				tok.quoted = true
+				i += end + 2
+			}
+			tok.text = text.String()
+			tokens = append(tokens, tok)
+		}
+	}
+	return tokens, nil
+}
+
+// parser
+
+type queryParser struct {
+	tokens []*queryToken
+	index  int
+	end    int
+}
+
+func (p *queryParser) peek() *queryToken {
+	if p.index >= len(p.tokens) {
+		return nil
+	}
+	return p.tokens[p.index]
+}
+
+func (p *queryParser) next() *queryToken {
+	tok := p.peek()
+	p.index++
+	return tok
+}
+
+func (p *queryParser) parseOr() (queryExpr, error) {
+	expr, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	exprs := orExpr{expr}
+	for p.peek().is("or") {
+		p.next()
+		expr, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	return exprs, nil
+}
+
+func (p *queryParser) parseAnd() (queryExpr, error) {
+	var exprs andExpr
+	for {
+		tok := p.peek()
+		if tok == nil || tok.is(")") || tok.is("or") {
+			break
+		}
+
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+
+	if len(exprs) == 0 {
+		pos := p.end
+		if tok := p.peek(); tok != nil {
+			pos = tok.pos
+		}
+		return nil, &QueryError{Position: pos, Message: "expected a search term"}
+	}
+
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	return exprs, nil
+}
+
+func (p *queryParser) parseUnary() (queryExpr, error) {
+	tok := p.next()
+
+	// negated group: -( ... )
+	if tok.is("-") {
+		if next := p.peek(); next.is("(") && next.pos == tok.pos+1 {
+			expr, err := p.parseUnary()
+			if err != nil {
+				return nil, err
+			}
+			return notExpr{expr}, nil
+		}
+		return nil, &QueryError{Position: tok.pos, Message: "nothing to negate"}
+	}
+
+	if tok.is("(") {
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); !closing.is(")") {
+			return nil, &QueryError{Position: p.end, Message: "missing closing parenthesis"}
+		}
+		return expr, nil
+	}
+
+	if tok.is(")") {
+		return nil, &QueryError{Position: tok.pos, Message: "unexpected closing parenthesis"}
+	}
+
+	return parseTerm(tok)
+}
+
+var termPattern = regexp.MustCompile(`^(-?)([a-zA-Z]+)(:|!=|<=|>=|=|<|>)(.*)$`)
+
+// parseTerm turns a single word into a filter
+func parseTerm(tok *queryToken) (queryExpr, error) {
+	text := tok.text
+	negate := false
+
+	match := termPattern.FindStringSubmatch(text)
+	if match == nil || tok.literal {
+		// plain card name
+		if !tok.literal && strings.HasPrefix(text, "-") {
+			negate = true
+			text = text[1:]
+		}
+		name := strings.ToLower(text)
+		var expr queryExpr = termExpr(func(card CardData) bool {
+			return strings.Contains(strings.ToLower(card.Name), name)
+		})
+		if negate {
+			expr = notExpr{expr}
+		}
+		return expr, nil
+	}
+
+	negate = match[1] == "-"
+	key, op, value := strings.ToLower(match[2]), match[3], match[4]
+	keyPos := tok.pos + len(match[1])
+	valuePos := keyPos + len(match[2]) + len(op)
+
+	if value == "" {
+		return nil, &QueryError{Position: valuePos, Message: "missing value for " + key}
+	}
+
+	var expr queryExpr
+	var err error
+	switch key {
+	case "r", "rarity":
+		expr, err = rarityTerm(op, value, valuePos)
+	case "c", "color":
+		expr, err = colorTerm(op, value, valuePos, ">=", func(card CardData) []string {
+			return card.colors(ColorSourceColors)
+		})
+	case "id", "identity":
+		expr, err = colorTerm(op, value, valuePos, "<=", func(card CardData) []string {
+			return card.ColorIdentity
+		})
+	case "cmc", "mv":
+		expr, err = cmcTerm(op, value, valuePos)
+	case "t", "type":
+		expr, err = typeTerm(op, value, valuePos)
+	case "s", "e", "set":
+		expr, err = setTerm(op, value, valuePos)
+	case "n", "name":
+		expr, err = nameTerm(op, value, valuePos)
+	case "in":
+		expr, err = inTerm(op, value, valuePos)
+	default:
+		return nil, &QueryError{Position: keyPos, Message: "unknown key " + key}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if negate {
+		expr = notExpr{expr}
+	}
+	return expr, nil
+}
+
+// compare applies a comparison operator to the result of a three-way comparison
+func compare(op string, cmp int) bool {
+	switch op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "!=":
+		return cmp != 0
+	}
+	return cmp == 0
+}
+
+func equalityOnly(op string, pos int, key string) error {
+	if op == ":" || op == "=" || op == "!=" {
+		return nil
+	}
+	return &QueryError{Position: pos - len(op), Message: "operator " + op + " not supported for " + key}
+}
+
+func rarityTerm(op string, value string, pos int) (queryExpr, error) {
+	rarityIndex := func(rarity string) int {
+		for i, r := range rarities {
+			if r == rarity || len(rarity) == 1 && r[:1] == rarity {
+				return i
+			}
+		}
+		return -1
+	}
+
+	wanted := rarityIndex(strings.ToLower(value))
+	if wanted < 0 {
+		return nil, &QueryError{Position: pos, Message: "unknown rarity " + value}
+	}
+
+	return termExpr(func(card CardData) bool {
+		return compare(op, rarityIndex(card.Rarity)-wanted)
+	}), nil
+}
+
+func colorTerm(op string, value string, pos int, colonOp string, colors func(CardData) []string) (queryExpr, error) {
+	wanted := map[string]bool{}
+	switch strings.ToLower(value) {
+	case "c", "colorless":
+		// every card has at least no colors, so colorless can only mean exactly none
+		if op == ":" {
+			colonOp = "="
+		}
+	case "white":
+		wanted["W"] = true
+	case "blue":
+		wanted["U"] = true
+	case "black":
+		wanted["B"] = true
+	case "red":
+		wanted["R"] = true
+	case "green":
+		wanted["G"] = true
+	default:
+		for i, c := range strings.ToUpper(value) {
+			if !strings.ContainsRune("WUBRG", c) {
+				return nil, &QueryError{Position: pos + i, Message: "unknown color " + string(c)}
+			}
+			wanted[string(c)] = true
+		}
+	}
+
+	if op == ":" {
+		op = colonOp
+	}
+
+	return termExpr(func(card CardData) bool {
+		have := map[string]bool{}
+		for _, color := range colors(card) {
+			have[color] = true
+		}
+
+		subset, superset := true, true
+		for color := range have {
+			if !wanted[color] {
+				subset = false
+			}
+		}
+		for color := range wanted {
+			if !have[color] {
+				superset = false
+			}
+		}
+
+		switch op {
+		case "<=":
+			return subset
+		case "<":
+			return subset && !superset
+		case ">=":
+			return superset
+		case ">":
+			return superset && !subset
+		case "!=":
+			return !subset || !superset
+		}
+		return subset && superset
+	}), nil
+}
+
+func cmcTerm(op string, value string, pos int) (queryExpr, error) {
+	wanted, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return nil, &QueryError{Position: pos, Message: "invalid mana value " + value}
+	}
+
+	return termExpr(func(card CardData) bool {
+		return compare(op, int(card.CMC)-int(wanted))
+	}), nil
+}
+
+func typeTerm(op string, value string, pos int) (queryExpr, error) {
+	if err := equalityOnly(op, pos, "type"); err != nil {
+		return nil, err
+	}
+
+	wanted := strings.ToLower(value)
+	return termExpr(func(card CardData) bool {
+		for _, cardType := range card.Types() {
+			if cardType == wanted {
+				return op != "!="
+			}
+		}
+		return op == "!="
+	}), nil
+}
+
+func setTerm(op string, value string, pos int) (queryExpr, error) {
+	if err := equalityOnly(op, pos, "set"); err != nil {
+		return nil, err
+	}
+
+	wanted := map[string]bool{}
+	for _, set := range strings.Split(strings.ToLower(value), ",") {
+		if set == "" {
+			return nil, &QueryError{Position: pos, Message: "empty set in list " + value}
+		}
+		wanted[set] = true
+	}
+
+	return termExpr(func(card CardData) bool {
+		return wanted[card.Set] != (op == "!=")
+	}), nil
+}
+
+func nameTerm(op string, value string, pos int) (queryExpr, error) {
+	if err := equalityOnly(op, pos, "name"); err != nil {
+		return nil, err
+	}
+
+	wanted := strings.ToLower(value)
+	return termExpr(func(card CardData) bool {
+		name := strings.ToLower(card.Name)
+		switch op {
+		case "=":
+			return name == wanted
+		case "!=":
+			return name != wanted
+		}
+		return strings.Contains(name, wanted)
+	}), nil
+}
+
+func inTerm(op string, value string, pos int) (queryExpr, error) {
+	if err := equalityOnly(op, pos, "in"); err != nil {
+		return nil, err
+	}
+
+	if strings.ToLower(value) != "booster" {
+		return nil, &QueryError{Position: pos, Message: "unknown value for in: " + value}
+	}
+
+	return termExpr(func(card CardData) bool {
+		return card.InBooster != (op == "!=")
+	}), nil
+}
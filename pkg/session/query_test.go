@@ -0,0 +1,166 @@
+package session
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+var queryCards = []CardData{
+	{Name: "Llanowar Elves", Set: "dom", Rarity: "common", CMC: 1, Colors: []string{"G"}, ColorIdentity: []string{"G"}, TypeLine: "Creature — Elf Druid", InBooster: true},
+	{Name: "Shivan Dragon", Set: "m19", Rarity: "rare", CMC: 6, Colors: []string{"R"}, ColorIdentity: []string{"R"}, TypeLine: "Creature — Dragon", InBooster: true},
+	{Name: "Niv-Mizzet, Parun", Set: "grn", Rarity: "rare", CMC: 6, Colors: []string{"U", "R"}, ColorIdentity: []string{"U", "R"}, TypeLine: "Legendary Creature — Dragon Wizard", InBooster: true},
+	{Name: "Forest", Set: "dom", Rarity: "common", CMC: 0, Colors: []string{}, ColorIdentity: []string{"G"}, TypeLine: "Basic Land — Forest", InBooster: false},
+	{Name: "Opt", Set: "xln", Rarity: "common", CMC: 1, Colors: []string{"U"}, ColorIdentity: []string{"U"}, TypeLine: "Instant", InBooster: true},
+	{Name: "Teferi, Time Raveler", Set: "war", Rarity: "mythic", CMC: 3, Colors: []string{"W", "U"}, ColorIdentity: []string{"W", "U"}, TypeLine: "Legendary Planeswalker — Teferi", InBooster: true},
+}
+
+func TestParseQuery(t *testing.T) {
+	all := []string{"Forest", "Llanowar Elves", "Niv-Mizzet, Parun", "Opt", "Shivan Dragon", "Teferi, Time Raveler"}
+
+	tests := []struct {
+		query   string
+		matches []string
+	}{
+		{"", all},
+		{"   ", all},
+		{"dragon", []string{"Shivan Dragon"}},
+		{"-dragon", []string{"Forest", "Llanowar Elves", "Niv-Mizzet, Parun", "Opt", "Teferi, Time Raveler"}},
+		{`"shivan dragon"`, []string{"Shivan Dragon"}},
+		{`"t:dragon"`, nil},
+		{`name:"time raveler"`, []string{"Teferi, Time Raveler"}},
+		{"n=opt", []string{"Opt"}},
+		{"t:dragon", []string{"Niv-Mizzet, Parun", "Shivan Dragon"}},
+		{"T:Dragon R>=RARE", []string{"Niv-Mizzet, Parun", "Shivan Dragon"}},
+		{"-t:land", []string{"Llanowar Elves", "Niv-Mizzet, Parun", "Opt", "Shivan Dragon", "Teferi, Time Raveler"}},
+		{"type!=creature", []string{"Forest", "Opt", "Teferi, Time Raveler"}},
+		{"r>=rare", []string{"Niv-Mizzet, Parun", "Shivan Dragon", "Teferi, Time Raveler"}},
+		{"r:m", []string{"Teferi, Time Raveler"}},
+		{"rarity<uncommon", []string{"Forest", "Llanowar Elves", "Opt"}},
+		{"c:u", []string{"Niv-Mizzet, Parun", "Opt", "Teferi, Time Raveler"}},
+		{"c<=ur", []string{"Forest", "Niv-Mizzet, Parun", "Opt", "Shivan Dragon"}},
+		{"c=c", []string{"Forest"}},
+		{"c:c", []string{"Forest"}},
+		{"c:colorless", []string{"Forest"}},
+		{"-c:c", []string{"Llanowar Elves", "Niv-Mizzet, Parun", "Opt", "Shivan Dragon", "Teferi, Time Raveler"}},
+		{"c>=c", []string{"Forest", "Llanowar Elves", "Niv-Mizzet, Parun", "Opt", "Shivan Dragon", "Teferi, Time Raveler"}},
+		{"color>blue", []string{"Niv-Mizzet, Parun", "Teferi, Time Raveler"}},
+		{"id:wu", []string{"Opt", "Teferi, Time Raveler"}},
+		{"id!=g", []string{"Niv-Mizzet, Parun", "Opt", "Shivan Dragon", "Teferi, Time Raveler"}},
+		{"cmc>=6", []string{"Niv-Mizzet, Parun", "Shivan Dragon"}},
+		{"mv<2 t:creature", []string{"Llanowar Elves"}},
+		{"s:dom,war", []string{"Forest", "Llanowar Elves", "Teferi, Time Raveler"}},
+		{"set!=dom", []string{"Niv-Mizzet, Parun", "Opt", "Shivan Dragon", "Teferi, Time Raveler"}},
+		{"in:booster", []string{"Llanowar Elves", "Niv-Mizzet, Parun", "Opt", "Shivan Dragon", "Teferi, Time Raveler"}},
+		{"-in:booster", []string{"Forest"}},
+		{"t:dragon or t:elf", []string{"Llanowar Elves", "Niv-Mizzet, Parun", "Shivan Dragon"}},
+		{"t:dragon OR t:elf c:g", []string{"Llanowar Elves", "Niv-Mizzet, Parun", "Shivan Dragon"}},
+		{"-(t:creature or t:land)", []string{"Opt", "Teferi, Time Raveler"}},
+		{"(r:rare or r:mythic) -c:r", []string{"Teferi, Time Raveler"}},
+		{"((cmc=1))", []string{"Llanowar Elves", "Opt"}},
+	}
+
+	for _, test := range tests {
+		q, err := ParseQuery(test.query)
+		if err != nil {
+			t.Errorf("%q: %v", test.query, err)
+			continue
+		}
+
+		var matches []string
+		for _, card := range queryCards {
+			if q.Match(card) {
+				matches = append(matches, card.Name)
+			}
+		}
+		sort.Strings(matches)
+
+		if !reflect.DeepEqual(matches, test.matches) {
+			t.Errorf("%q: expected %v, got %v", test.query, test.matches, matches)
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		query    string
+		position int
+		message  string
+	}{
+		{`"unterminated`, 0, "unterminated quote"},
+		{`name:"abc`, 5, "unterminated quote"},
+		{"foo:bar", 0, "unknown key foo"},
+		{"r:rare -foo:bar", 8, "unknown key foo"},
+		{"r:", 2, "missing value for r"},
+		{"r>=foo", 3, "unknown rarity foo"},
+		{"cmc>x", 4, "invalid mana value x"},
+		{"c:uxg", 3, "unknown color X"},
+		{"t>=land", 1, "operator >= not supported for type"},
+		{"s:war,,rna", 2, "empty set in list war,,rna"},
+		{"in:graveyard", 3, "unknown value for in: graveyard"},
+		{"(t:land", 7, "missing closing parenthesis"},
+		{"-(t:land", 8, "missing closing parenthesis"},
+		{"t:land)", 6, `unexpected ")"`},
+		{")", 0, "expected a search term"},
+		{"()", 1, "expected a search term"},
+		{"r:rare or", 9, "expected a search term"},
+		{"or r:rare", 0, "expected a search term"},
+		{"- t:land", 0, "nothing to negate"},
+	}
+
+	for _, test := range tests {
+		_, err := ParseQuery(test.query)
+		queryErr, ok := err.(*QueryError)
+		if !ok {
+			t.Errorf("%q: expected a query error, got %v", test.query, err)
+			continue
+		}
+
+		if queryErr.Position != test.position || queryErr.Message != test.message {
+			t.Errorf("%q: expected %q at %d, got %q at %d", test.query, test.message, test.position, queryErr.Message, queryErr.Position)
+		}
+	}
+}
+
+func TestQueryUsesTypes(t *testing.T) {
+	tests := map[string]bool{
+		"":                   false,
+		"r:rare":             false,
+		"t:land":             true,
+		"-type:creature":     true,
+		"r:rare or (T:land)": true,
+		`"t:land"`:           false,
+		"tribal":             false,
+	}
+
+	for query, expected := range tests {
+		q, err := ParseQuery(query)
+		if err != nil {
+			t.Fatalf("%q: %v", query, err)
+		}
+		if q.UsesTypes() != expected {
+			t.Errorf("%q: expected %v", query, expected)
+		}
+	}
+}
+
+func TestQueryUsesColors(t *testing.T) {
+	tests := map[string]bool{
+		"":              false,
+		"id:wu":         false,
+		"c:wu":          true,
+		"-color<=r":     true,
+		"r:rare or C:c": true,
+		`"c:wu"`:        false,
+	}
+
+	for query, expected := range tests {
+		q, err := ParseQuery(query)
+		if err != nil {
+			t.Fatalf("%q: %v", query, err)
+		}
+		if q.UsesColors() != expected {
+			t.Errorf("%q: expected %v", query, expected)
+		}
+	}
+}
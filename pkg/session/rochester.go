@@ -0,0 +1,236 @@
+package session
+
+import "time"
+
+func init() {
+	RegisterMode(ModeRochester, rochester{})
+}
+
+// rochester is a draft where one pack at a time is opened face-up and everybody picks from it in snake order
+type rochester struct {
+	packValidation
+}
+
+// Rochester contains the state of a Rochester draft. Everything but the unopened packs is public
+type Rochester struct {
+	Players  []string             `firestore:"players" json:"players"`
+	Round    int                  `firestore:"round" json:"round"`
+	Opener   int                  `firestore:"opener" json:"opener"`
+	Pick     int                  `firestore:"pick" json:"pick"`
+	Turn     string               `firestore:"turn" json:"turn"`
+	Deadline time.Time            `firestore:"deadline" json:"deadline"`
+	Pack     Pack                 `firestore:"pack" json:"pack"`
+	Picks    map[string][]ArenaID `firestore:"picks" json:"picks"`
+	Finished bool                 `firestore:"finished" json:"finished"`
+	Log      []PickLog            `firestore:"log" json:"-"`
+
+	Unopened []Pack `firestore:"unopened" json:"-"`
+}
+
+func (r *Rochester) copy() *Rochester {
+	if r == nil {
+		return nil
+	}
+
+	out := *r
+	out.Picks = make(map[string][]ArenaID, len(r.Picks))
+	for playerID, picks := range r.Picks {
+		out.Picks[playerID] = append([]ArenaID(nil), picks...)
+	}
+	out.Unopened = append([]Pack(nil), r.Unopened...)
+	out.Log = append([]PickLog(nil), r.Log...)
+	return &out
+}
+
+// Start generates all packs and opens the first one
+func (rochester) Start(s *Session, cardDB CardDB) error {
+	rng := s.rand()
+	pool := s.pool(cardDB, rng)
+
+	playerIDs := s.playerIDs()
+	count := s.Options.PackOptions.count(DefaultDraftPackCount)
+
+	packs, err := generatePacks(cardDB, pool, s.Options.PackOptions, s.Options.Collation, count*len(playerIDs), rng)
+	if err != nil {
+		return err
+	}
+
+	r := &Rochester{
+		Players:  playerIDs,
+		Picks:    map[string][]ArenaID{},
+		Unopened: packs,
+		Opener:   -1,
+	}
+	for _, player := range s.Players {
+		player.SessionCollection = Collection{}
+	}
+
+	r.open(s.Options.pickTime(), time.Now())
+	s.Rochester = r
+	return nil
+}
+
+// Finished checks if all picks are made
+func (rochester) Finished(s *Session) bool {
+	return s.Rochester != nil && s.Rochester.Finished
+}
+
+// HandleAction takes a card out of the open pack, if it is the player's turn
+func (rochester) HandleAction(s *Session, cardDB CardDB, playerID string, action PlayerAction) error {
+	if action.Action != ActionPick {
+		return ErrInvalidAction
+	}
+
+	r := s.Rochester
+	if r.Finished {
+		return ErrDraftFinished
+	}
+	if r.Turn != playerID {
+		return ErrNotYourTurn
+	}
+
+	now := time.Now()
+	if err := r.pick(s, action.Card, now); err != nil {
+		return err
+	}
+
+	r.standIn(s, cardDB, now)
+	return nil
+}
+
+// Expire picks for the active player once their time is up
+func (rochester) Expire(s *Session, cardDB CardDB, now time.Time) bool {
+	r := s.Rochester
+	if r == nil || r.Finished || r.Deadline.IsZero() || now.Before(r.Deadline) {
+		return false
+	}
+
+	if r.pick(s, autoPick(cardDB, r.Pack.Cards), now) != nil {
+		return false
+	}
+
+	r.standIn(s, cardDB, now)
+	return true
+}
+
+// Leave picks for the player whenever it is their turn
+func (rochester) Leave(s *Session, cardDB CardDB, playerID string, now time.Time) {
+	if s.Rochester != nil {
+		s.Rochester.standIn(s, cardDB, now)
+	}
+}
+
+// Deadlines returns when the active player runs out of time
+func (rochester) Deadlines(s *Session) map[string]time.Time {
+	r := s.Rochester
+	if r == nil || r.Finished || r.Deadline.IsZero() {
+		return nil
+	}
+	return map[string]time.Time{r.Turn: r.Deadline}
+}
+
+// PickLog returns all picks so far
+func (rochester) PickLog(s *Session) []PickLog {
+	if s.Rochester == nil {
+		return nil
+	}
+	return s.Rochester.Log
+}
+
+func (r *Rochester) pick(s *Session, arenaID ArenaID, now time.Time) error {
+	index := -1
+	for i, card := range r.Pack.Cards {
+		if card == arenaID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return ErrInvalidPick
+	}
+
+	r.Log = append(r.Log, PickLog{
+		PlayerID: r.Turn,
+		Pack:     r.Round*len(r.Players) + r.Opener,
+		Pick:     r.Pick,
+		Offered:  r.Pack.Cards,
+		Card:     arenaID,
+		Time:     now,
+	})
+
+	cards := make([]ArenaID, 0, len(r.Pack.Cards)-1)
+	cards = append(cards, r.Pack.Cards[:index]...)
+	r.Pack.Cards = append(cards, r.Pack.Cards[index+1:]...)
+
+	r.Picks[r.Turn] = append(r.Picks[r.Turn], arenaID)
+	s.give(r.Turn, arenaID)
+
+	if len(r.Pack.Cards) == 0 {
+		r.open(s.Options.pickTime(), now)
+		return nil
+	}
+
+	r.Pick++
+	r.Turn = r.snake()
+	r.resetDeadline(s.Options.pickTime(), now)
+	return nil
+}
+
+// standIn picks for players who left using the bot strategy of the session, until it is the turn of a player still there
+func (r *Rochester) standIn(s *Session, cardDB CardDB, now time.Time) {
+	strategy, ok := LookupBotStrategy(s.Options.BotStrategy)
+	if !ok {
+		return
+	}
+
+	for !r.Finished {
+		if _, ok := s.Players[r.Turn]; ok {
+			return
+		}
+
+		arenaID := strategy.Pick(cardDB, s.Options, r.Picks[r.Turn], r.Pack.Cards)
+		if r.pick(s, arenaID, now) != nil {
+			return
+		}
+	}
+}
+
+// open reveals the next pack, opened by the next player at the table
+func (r *Rochester) open(pickTime time.Duration, now time.Time) {
+	if len(r.Unopened) == 0 {
+		r.Finished = true
+		r.Turn = ""
+		r.Deadline = time.Time{}
+		return
+	}
+
+	r.Pack = r.Unopened[0]
+	r.Unopened = r.Unopened[1:]
+	r.Opener++
+	if r.Opener == len(r.Players) {
+		// everybody opened a pack, next round
+		r.Opener = 0
+		r.Round++
+	}
+	r.Pick = 0
+	r.Turn = r.snake()
+	r.resetDeadline(pickTime, now)
+}
+
+// snake returns the player for the current pick: from the opener around the table and back again.
+// The direction changes every round
+func (r *Rochester) snake() string {
+	n := len(r.Players)
+	step := r.Pick % (2 * n)
+	if step >= n {
+		step = 2*n - 1 - step
+	}
+	if r.Round%2 == 1 {
+		step = -step
+	}
+	return r.Players[((r.Opener+step)%n+n)%n]
+}
+
+func (r *Rochester) resetDeadline(pickTime time.Duration, now time.Time) {
+	r.Deadline = deadline(pickTime, now)
+}
@@ -0,0 +1,51 @@
+package session
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRochesterPlaysToFinish(t *testing.T) {
+	cardDB, collection := testCards(60)
+	opts := Options{Mode: ModeRochester, Seed: 1, PackOptions: PackOptions{Count: 2, Size: 4}}
+	s := startTestSession(t, cardDB, collection, opts, "a", "b", "c")
+	r := s.Rochester
+
+	var turns []string
+	for picks := 0; !r.Finished; picks++ {
+		if picks > 2*3*4 {
+			t.Fatal("draft did not finish")
+		}
+
+		other := "a"
+		if other == r.Turn {
+			other = "b"
+		}
+		if err := s.HandleAction(cardDB, other, PlayerAction{Action: ActionPick, Card: r.Pack.Cards[0]}); err != ErrNotYourTurn {
+			t.Fatalf("expected %v, got %v", ErrNotYourTurn, err)
+		}
+
+		turns = append(turns, r.Turn)
+		if err := s.HandleAction(cardDB, r.Turn, PlayerAction{Action: ActionPick, Card: r.Pack.Cards[0]}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// every pack snakes from its opener around the table and back, the direction changes every round
+	expected := "abcc bcaa cabb acbb bacc cbaa"
+	if got := strings.Join(turns, ""); got != strings.Replace(expected, " ", "", -1) {
+		t.Errorf("expected turns %s, got %s", expected, got)
+	}
+
+	for _, playerID := range []string{"a", "b", "c"} {
+		if got := len(s.Players[playerID].SessionCollection); got != 2*4 {
+			t.Errorf("%s drafted %d cards", playerID, got)
+		}
+		if got := len(r.Picks[playerID]); got != 2*4 {
+			t.Errorf("%s has %d public picks", playerID, got)
+		}
+	}
+	if r.Turn != "" || !r.Deadline.IsZero() {
+		t.Errorf("finished draft still waits for %q until %v", r.Turn, r.Deadline)
+	}
+}
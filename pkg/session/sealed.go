@@ -0,0 +1,32 @@
+package session
+
+func init() {
+	RegisterMode(ModeSealed, sealed{})
+}
+
+// sealed hands every player their own set of boosters generated from the pool
+type sealed struct {
+	noActions
+	packValidation
+}
+
+func (sealed) Start(s *Session, cardDB CardDB) error {
+	rng := s.rand()
+	pool := s.pool(cardDB, rng)
+
+	collections := map[string]Collection{}
+	for _, playerID := range s.playerIDs() {
+		packs, err := generatePacks(cardDB, pool, s.Options.PackOptions, s.Options.Collation, s.Options.PackOptions.count(DefaultPackCount), rng)
+		if err != nil {
+			return err
+		}
+		collections[playerID] = Packs(packs)
+	}
+
+	// only write back once all packs could be generated
+	for playerID, collection := range collections {
+		s.Players[playerID].SessionCollection = collection
+	}
+
+	return nil
+}
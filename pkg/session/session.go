@@ -1,11 +1,24 @@
 package session
 
+import (
+	crand "crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+)
+
 // PlayerData contains the player's session information
 type PlayerData struct {
 	PlayerName
 	PlayerUpdate
 	CompleteCollection Collection `firestore:"complete_collection" json:"-"`
 	SessionCollection  Collection `firestore:"session_collection" json:"-"`
+	// Token is only known to the player, it proves their identity when they return
+	Token string `firestore:"token" json:"-"`
 }
 
 // PlayerName is a placeholder for a player's name
@@ -13,36 +26,219 @@ type PlayerName struct {
 	Name string `firestore:"name" json:"name"`
 }
 
-// PlayerRegistration contains the data needed for adding a new player
+// PlayerRegistration contains the data needed for adding a new player.
+// Players of asynchronous sessions return with their PlayerID and Token instead
 type PlayerRegistration struct {
 	PlayerName
 	Collection `firestore:"collection" json:"collection"`
+	PlayerID   string `firestore:"player_id" json:"player_id"`
+	Token      string `firestore:"token" json:"token"`
 }
 
-// PlayerUpdate contains data provided when sending an update
+// PlayerUpdate contains data provided when sending an update. Players with the same Team share a pool in team modes.
+// Updates without a team keep the current one, an empty team leaves it
 type PlayerUpdate struct {
-	Ready bool `firestore:"ready" json:"ready"`
+	Ready bool    `firestore:"ready" json:"ready"`
+	Team  *string `firestore:"team" json:"team,omitempty"`
+}
+
+// PlayerAction contains an in-game action of a player, e.g. a draft pick
+type PlayerAction struct {
+	Action string  `json:"action"`
+	Card   ArenaID `json:"card"`
+	Index  int     `json:"index"`
+}
+
+// PlayerMessage is anything a player sends over the lobby. Messages without an action are updates
+type PlayerMessage struct {
+	PlayerUpdate
+	PlayerAction
 }
 
+// Pooling modes, i.e. how the player collections are combined
+const (
+	// PoolingIntersection only uses cards every player owns (default)
+	PoolingIntersection = "intersection"
+	// PoolingUnion uses cards any player owns
+	PoolingUnion = "union"
+	// PoolingThreshold uses cards owned by at least Threshold players
+	PoolingThreshold = "threshold"
+)
+
+// Player actions
+const (
+	ActionPick   = "pick"
+	ActionLook   = "look"
+	ActionTake   = "take"
+	ActionPass   = "pass"
+	ActionRow    = "row"
+	ActionColumn = "column"
+)
+
 // Options describes which kind of game is played
 type Options struct {
-	Singleton     bool   `firestore:"singleton" json:"singleton"`
-	Set           string `firestore:"set" json:"set"`
+	Mode          string       `firestore:"mode" json:"mode"`
+	Singleton     bool         `firestore:"singleton" json:"singleton"`
+	BoosterOnly   bool         `firestore:"booster_only" json:"booster_only"`
+	Set           string       `firestore:"set" json:"set"`
+	Sets          []string     `firestore:"sets" json:"sets"`
+	AnyPrinting   bool         `firestore:"any_printing" json:"any_printing"`
+	Banlist       []string     `firestore:"banlist" json:"banlist"`
+	Allowlist     []string     `firestore:"allowlist" json:"allowlist"`
+	Query         string       `firestore:"query" json:"query"`
+	Pooling       string       `firestore:"pooling" json:"pooling"`
+	Threshold     int          `firestore:"threshold" json:"threshold"`
+	PoolSize      int          `firestore:"pool_size" json:"pool_size"`
+	PoolQuotas    RarityQuotas `firestore:"pool_quotas" json:"pool_quotas"`
+	Seed          int64        `firestore:"seed" json:"seed"`
+	Cube          string       `firestore:"cube" json:"cube"`
+	CubeID        string       `firestore:"cube_id" json:"cube_id"`
+	PickTime      int          `firestore:"pick_time" json:"pick_time"`
+	ChaosSets     []string     `firestore:"chaos_sets" json:"chaos_sets"`
+	Bots          int          `firestore:"bots" json:"bots"`
+	BotStrategy   string       `firestore:"bot_strategy" json:"bot_strategy"`
+	BotRatings    CardRatings  `firestore:"bot_ratings" json:"bot_ratings"`
+	BotPools      bool         `firestore:"bot_pools" json:"bot_pools"`
+	Async         bool         `firestore:"async" json:"async"`
+	CubeIntersect bool         `firestore:"cube_intersect" json:"cube_intersect"`
 	RarityOptions `firestore:"rarity" json:"rarity"`
 	ColorOptions  `json:"color"`
+	CMCOptions    `firestore:"cmc" json:"cmc"`
+	TypeOptions   `firestore:"types" json:"types"`
+	PackOptions   `firestore:"packs" json:"packs"`
+	Collation     `firestore:"collation" json:"collation"`
+}
+
+// pickTime returns the time limit per pick (0 == unlimited)
+func (o Options) pickTime() time.Duration {
+	return time.Duration(o.PickTime) * time.Second
+}
+
+// sets returns all configured sets, including the single Set
+func (o Options) sets() []string {
+	if o.Set == "" {
+		return o.Sets
+	}
+	return append([]string{o.Set}, o.Sets...)
+}
+
+// Validate checks if the options describe a playable game
+func (o Options) Validate(cardDB CardDB) error {
+	if o.CMCOptions.Min != nil && o.CMCOptions.Max != nil && *o.CMCOptions.Min > *o.CMCOptions.Max {
+		return ErrInvalidCMCRange
+	}
+
+	if err := o.ColorOptions.Validate(); err != nil {
+		return err
+	}
+
+	if o.PickTime < 0 {
+		return ErrInvalidPickTime
+	}
+
+	if o.Bots < 0 {
+		return ErrInvalidBots
+	}
+
+	if o.PoolSize < 0 {
+		return ErrInvalidPoolSize
+	}
+	q := o.PoolQuotas
+	if q.Common < 0 || q.Uncommon < 0 || q.Rare < 0 || q.Mythic < 0 {
+		return ErrInvalidPoolSize
+	}
+
+	switch o.Pooling {
+	case "", PoolingIntersection, PoolingUnion:
+	case PoolingThreshold:
+		if o.Threshold < 1 {
+			return ErrInvalidPooling
+		}
+	default:
+		return ErrInvalidPooling
+	}
+
+	query, err := ParseQuery(o.Query)
+	if err != nil {
+		return err
+	}
+
+	// without type lines, type filters would remove every card or none at all
+	usesTypes := len(o.TypeOptions.Include) > 0 || len(o.TypeOptions.Exclude) > 0 || query.UsesTypes()
+	if usesTypes && !cardDB.HasTypes() {
+		return ErrNoTypeData
+	}
+
+	// matching on casting colors would silently fall back to the color identity
+	usesColors := o.ColorOptions.Source == ColorSourceColors || query.UsesColors()
+	if usesColors && !cardDB.HasColors() {
+		return ErrNoColorData
+	}
+
+	// every card on the lists needs to be known
+	_, unresolvedBans := cardDB.Resolve(o.Banlist)
+	_, unresolvedAllows := cardDB.Resolve(o.Allowlist)
+	_, unresolvedCube := ParseCube(cardDB, o.Cube)
+	unresolved := append(unresolvedBans, unresolvedAllows...)
+	if unresolved = append(unresolved, unresolvedCube...); len(unresolved) > 0 {
+		return &UnresolvedCardsError{Names: unresolved}
+	}
+
+	mode, ok := LookupMode(o.Mode)
+	if !ok {
+		return ErrUnknownMode
+	}
+
+	// bots need empty seats to fill
+	if o.Bots > 0 {
+		seater, ok := mode.(botSeater)
+		if !ok {
+			return ErrBotsNotSupported
+		}
+		if o.Bots >= seater.BotSeats() {
+			return ErrInvalidBots
+		}
+	}
+
+	if _, ok := mode.(selfPaced); o.Async && !ok {
+		return ErrAsyncNotSupported
+	}
+
+	return mode.Validate(o)
 }
 
-// ColorOptions contains all settings related to colors. false == keep
+// Color matching modes
+const (
+	// ColorMatchSubset keeps cards whose colors are all enabled (default)
+	ColorMatchSubset = "subset"
+	// ColorMatchAny keeps cards that have at least one enabled color
+	ColorMatchAny = "any"
+	// ColorMatchExact keeps cards whose colors are exactly the enabled ones
+	ColorMatchExact = "exact"
+)
+
+// Color sources
+const (
+	// ColorSourceIdentity matches on the color identity (default)
+	ColorSourceIdentity = "identity"
+	// ColorSourceColors matches on the casting colors
+	ColorSourceColors = "colors"
+)
+
+// ColorOptions contains all settings related to colors. true == keep, nothing enabled == no filter.
+// Colorless cards are kept if Colorless is enabled, regardless of the match mode
 type ColorOptions struct {
-	White     bool `json:"white"`
-	Blue      bool `json:"blue"`
-	Black     bool `json:"black"`
-	Red       bool `json:"red"`
-	Green     bool `json:"green"`
-	Colorless bool `json:"colorless"`
+	White     bool   `json:"white"`
+	Blue      bool   `json:"blue"`
+	Black     bool   `json:"black"`
+	Red       bool   `json:"red"`
+	Green     bool   `json:"green"`
+	Colorless bool   `json:"colorless"`
+	Match     string `json:"match"`
+	Source    string `json:"source"`
 }
 
-// RarityOptions denotes which rarities should be filtered. false == keep
+// RarityOptions denotes which rarities should be kept. true == keep, nothing enabled == no filter
 type RarityOptions struct {
 	Common   bool `json:"common"`
 	Uncommon bool `json:"uncommon"`
@@ -50,6 +246,42 @@ type RarityOptions struct {
 	Mythic   bool `json:"mythic"`
 }
 
+// RarityQuotas are relative weights of the rarities in a sampled pool (all zero == no quotas)
+type RarityQuotas struct {
+	Common   int `json:"common"`
+	Uncommon int `json:"uncommon"`
+	Rare     int `json:"rare"`
+	Mythic   int `json:"mythic"`
+}
+
+// Lookup returns the weight of the given rarity string
+func (r RarityQuotas) Lookup(rarity string) int {
+	switch rarity {
+	case "common":
+		return r.Common
+	case "uncommon":
+		return r.Uncommon
+	case "rare":
+		return r.Rare
+	case "mythic":
+		return r.Mythic
+	}
+
+	return 0
+}
+
+// CMCOptions limits the mana value of cards (nil == unlimited)
+type CMCOptions struct {
+	Min *uint `firestore:"min" json:"min"`
+	Max *uint `firestore:"max" json:"max"`
+}
+
+// TypeOptions lists card types that have to be (any of Include) or must not be (Exclude) on a card
+type TypeOptions struct {
+	Include []string `firestore:"include" json:"include"`
+	Exclude []string `firestore:"exclude" json:"exclude"`
+}
+
 // Lookup does a lookup with the given color string
 func (c ColorOptions) Lookup(color string) bool {
 	switch color {
@@ -68,6 +300,64 @@ func (c ColorOptions) Lookup(color string) bool {
 	return false
 }
 
+// Enabled checks if any color is enabled, i.e. if the filter is applied at all
+func (c ColorOptions) Enabled() bool {
+	return c.White || c.Blue || c.Black || c.Red || c.Green || c.Colorless
+}
+
+// Validate checks for unknown match modes and color sources
+func (c ColorOptions) Validate() error {
+	switch c.Match {
+	case "", ColorMatchSubset, ColorMatchAny, ColorMatchExact:
+	default:
+		return ErrInvalidColorOptions
+	}
+
+	switch c.Source {
+	case "", ColorSourceIdentity, ColorSourceColors:
+	default:
+		return ErrInvalidColorOptions
+	}
+
+	return nil
+}
+
+// Matches checks if a card with the given colors is kept
+func (c ColorOptions) Matches(colors []string) bool {
+	if len(colors) == 0 {
+		return c.Colorless
+	}
+
+	switch c.Match {
+	case ColorMatchAny:
+		for _, color := range colors {
+			if c.Lookup(color) {
+				return true
+			}
+		}
+		return false
+
+	case ColorMatchExact:
+		enabled := 0
+		for _, color := range []string{"W", "U", "B", "R", "G"} {
+			if c.Lookup(color) {
+				enabled++
+			}
+		}
+		if enabled != len(colors) {
+			return false
+		}
+	}
+
+	// all colors need to match for it to stay in the collection
+	for _, color := range colors {
+		if !c.Lookup(color) {
+			return false
+		}
+	}
+	return true
+}
+
 // Lookup does a lookup for the given rarity string
 func (r RarityOptions) Lookup(rarity string) bool {
 	switch rarity {
@@ -84,32 +374,260 @@ func (r RarityOptions) Lookup(rarity string) bool {
 	return false
 }
 
+// Lookup checks if the given mana value is within range
+func (c CMCOptions) Lookup(cmc uint) bool {
+	if c.Min != nil && cmc < *c.Min {
+		return false
+	}
+	if c.Max != nil && cmc > *c.Max {
+		return false
+	}
+	return true
+}
+
+// Lookup checks if a card with the given types (see CardData.Types) matches
+func (t TypeOptions) Lookup(types []string) bool {
+	has := map[string]bool{}
+	for _, cardType := range types {
+		has[cardType] = true
+	}
+
+	for _, cardType := range t.Exclude {
+		if has[strings.ToLower(cardType)] {
+			return false
+		}
+	}
+
+	if len(t.Include) == 0 {
+		return true
+	}
+	for _, cardType := range t.Include {
+		if has[strings.ToLower(cardType)] {
+			return true
+		}
+	}
+	return false
+}
+
 // Session describes a playsession
 type Session struct {
 	Players map[string]*PlayerData `firestore:"players" json:"players"`
 	Started bool                   `firestore:"started" json:"started"`
 	Options
+	Seed      int64      `firestore:"seed" json:"-"`
+	Draft     *Draft     `firestore:"draft" json:"draft,omitempty"`
+	Winston   *Winston   `firestore:"winston" json:"winston,omitempty"`
+	Grid      *Grid      `firestore:"grid" json:"grid,omitempty"`
+	Rochester *Rochester `firestore:"rochester" json:"rochester,omitempty"`
+}
+
+// New creates an empty session. Without a seed in the options, a random one is picked
+func New(opts Options) *Session {
+	seed := opts.Seed
+	for seed == 0 {
+		seed = rand.New(rand.NewSource(time.Now().UnixNano())).Int63()
+	}
+
+	return &Session{
+		Players: map[string]*PlayerData{},
+		Options: opts,
+		Seed:    seed,
+	}
+}
+
+// MarshalJSON adds the team composition, and the seed once the game is over, so hidden cards can't be predicted before
+func (s Session) MarshalJSON() ([]byte, error) {
+	type session Session
+	out := struct {
+		session
+		Seed  *int64              `json:"seed,omitempty"`
+		Teams map[string][]string `json:"teams,omitempty"`
+	}{session: session(s), Teams: s.Teams()}
+
+	if s.finished() {
+		out.Seed = &s.Seed
+	}
+
+	return json.Marshal(out)
+}
+
+// Copy returns a deep copy of everything that changes during a game, so the copy can be read
+// while the original is updated. Options and complete collections are fixed once a player joined and are shared
+func (s *Session) Copy() *Session {
+	out := *s
+	out.Players = make(map[string]*PlayerData, len(s.Players))
+	for playerID, player := range s.Players {
+		p := *player
+		if player.SessionCollection != nil {
+			p.SessionCollection = player.SessionCollection.Copy()
+		}
+		out.Players[playerID] = &p
+	}
+
+	out.Draft = s.Draft.copy()
+	out.Winston = s.Winston.copy()
+	out.Grid = s.Grid.copy()
+	out.Rochester = s.Rochester.copy()
+	return &out
+}
+
+// rand returns the random source for the session. Every random step needs to draw from
+// a source created here (in a stable order), so that a session can be reproduced from its seed
+func (s *Session) rand() *rand.Rand {
+	return rand.New(rand.NewSource(s.Seed))
 }
 
 // UpdatePlayer updates the given player based on the PlayerUpdate
-func (s *Session) UpdatePlayer(cardDB CardDB, playerID string, update PlayerUpdate) {
+func (s *Session) UpdatePlayer(cardDB CardDB, playerID string, update PlayerUpdate) error {
 	if s.Started {
-		return
+		return nil
 	}
 
 	player, ok := s.Players[playerID]
 	if !ok {
-		return
+		return nil
 	}
 
+	if update.Team == nil {
+		update.Team = player.Team
+	}
 	player.PlayerUpdate = update
 
 	// Check if the update made the session "startable"; start if yes
-	s.startCheck(cardDB)
+	if err := s.startCheck(cardDB); err != nil {
+		// the session could not be started, don't keep the player waiting for it
+		player.Ready = false
+		return err
+	}
+	return nil
+}
+
+// HandleAction applies an in-game action of the given player
+func (s *Session) HandleAction(cardDB CardDB, playerID string, action PlayerAction) error {
+	if !s.Started {
+		return ErrNotStarted
+	}
+
+	if _, ok := s.Players[playerID]; !ok {
+		return ErrPlayerNotFound
+	}
+
+	mode, ok := LookupMode(s.Options.Mode)
+	if !ok {
+		return ErrUnknownMode
+	}
+
+	return mode.HandleAction(s, cardDB, playerID, action)
+}
+
+// View returns the hidden game state the given player is allowed to see (nil == nothing to see)
+func (s *Session) View(playerID string) interface{} {
+	if !s.Started {
+		return nil
+	}
+
+	mode, ok := LookupMode(s.Options.Mode)
+	if !ok {
+		return nil
+	}
+
+	v, ok := mode.(viewer)
+	if !ok {
+		return nil
+	}
+
+	return v.View(s, playerID)
+}
+
+// Expire makes the moves of all players that ran out of time, returns if anything changed
+func (s *Session) Expire(cardDB CardDB, now time.Time) bool {
+	if !s.Started {
+		return false
+	}
+
+	mode, ok := LookupMode(s.Options.Mode)
+	if !ok {
+		return false
+	}
+
+	t, ok := mode.(timed)
+	if !ok {
+		return false
+	}
+
+	return t.Expire(s, cardDB, now)
+}
+
+// finished checks if the game is over. Modes that are done once the pools are built finish when the session starts
+func (s *Session) finished() bool {
+	if !s.Started {
+		return false
+	}
+
+	mode, ok := LookupMode(s.Options.Mode)
+	if !ok {
+		return false
+	}
+
+	f, ok := mode.(finisher)
+	if !ok {
+		return true
+	}
+
+	return f.Finished(s)
+}
+
+// Deadlines returns until when each player has to make their move (nil == no time limits pending)
+func (s *Session) Deadlines() map[string]time.Time {
+	if !s.Started {
+		return nil
+	}
+
+	mode, ok := LookupMode(s.Options.Mode)
+	if !ok {
+		return nil
+	}
+
+	t, ok := mode.(timed)
+	if !ok {
+		return nil
+	}
+
+	return t.Deadlines(s)
+}
+
+// Rejoin checks if the given player may connect to the session again
+func (s *Session) Rejoin(playerID string, token string) error {
+	if !s.Options.Async {
+		return ErrRejoinNotAllowed
+	}
+	return s.Authenticate(playerID, token)
 }
 
-// RemovePlayer removes a player from the session
-func (s *Session) RemovePlayer(playerID string) {
+// Authenticate checks if the token belongs to the given player
+func (s *Session) Authenticate(playerID string, token string) error {
+	player, ok := s.Players[playerID]
+	if !ok {
+		return ErrPlayerNotFound
+	}
+
+	if player.Token == "" || subtle.ConstantTimeCompare([]byte(player.Token), []byte(token)) != 1 {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+// NewToken creates a random secret for a player
+func NewToken() (string, error) {
+	token := make([]byte, 24)
+	if _, err := crand.Read(token); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// RemovePlayer removes a player from the session. Games that already started go on without them
+func (s *Session) RemovePlayer(cardDB CardDB, playerID string) {
 	if _, ok := s.Players[playerID]; !ok {
 		return
 	}
@@ -119,41 +637,107 @@ func (s *Session) RemovePlayer(playerID string) {
 	for playerID := range s.Players {
 		s.Players[playerID].Ready = false
 	}
-}
 
-func (s *Session) startCheck(cardDB CardDB) {
-	if len(s.Players) < 2 {
+	if !s.Started {
 		return
 	}
 
+	mode, ok := LookupMode(s.Options.Mode)
+	if !ok {
+		return
+	}
+
+	if l, ok := mode.(leaver); ok {
+		l.Leave(s, cardDB, playerID, time.Now())
+	}
+}
+
+func (s *Session) startCheck(cardDB CardDB) error {
+	// bots make up for missing players
+	if len(s.Players) < 2 && s.Options.Bots == 0 {
+		return nil
+	}
+
 	// check if all players are ready
 	for _, player := range s.Players {
 		if !player.Ready {
-			return
+			return nil
 		}
 	}
 
 	// start session
-	// for now, only do "constructed. will change!
-	s.constructed(cardDB)
+	mode, ok := LookupMode(s.Options.Mode)
+	if !ok {
+		return ErrUnknownMode
+	}
+
+	if err := mode.Start(s, cardDB); err != nil {
+		return err
+	}
 
 	s.Started = true
+	return nil
+}
+
+// give adds the cards to the session collection of the player. Players who left the session
+// may still have moves made for them when their time is up, but don't get any cards
+func (s *Session) give(playerID string, cards ...ArenaID) {
+	player, ok := s.Players[playerID]
+	if !ok {
+		return
+	}
+
+	for _, arenaID := range cards {
+		player.SessionCollection[arenaID]++
+	}
 }
 
-func (s *Session) constructed(cardDB CardDB) {
-	// create intersection
+// playerIDs returns the IDs of all players in a stable order
+func (s *Session) playerIDs() []string {
+	ids := make([]string, 0, len(s.Players))
+	for playerID := range s.Players {
+		ids = append(ids, playerID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// pool combines all player collections according to the pooling mode and applies all filters
+func (s *Session) pool(cardDB CardDB, rng *rand.Rand) Collection {
+	collections := make([]Collection, 0, len(s.Players))
+	for _, playerID := range s.playerIDs() {
+		collections = append(collections, s.Players[playerID].CompleteCollection)
+	}
+
 	var collection Collection
-	for _, player := range s.Players {
-		if collection == nil {
-			collection = player.CompleteCollection.Copy()
-			continue
+	switch s.Options.Pooling {
+	case PoolingUnion:
+		collection = Threshold(collections, 1)
+	case PoolingThreshold:
+		// can't require more owners than there are players
+		threshold := s.Options.Threshold
+		if threshold > len(collections) {
+			threshold = len(collections)
 		}
+		collection = Threshold(collections, threshold)
+	default:
+		collection = collections[0].Copy()
+		for _, other := range collections[1:] {
+			collection.Intersect(other)
+		}
+	}
 
-		collection.Intersect(player.CompleteCollection)
+	// a cube replaces the player collections as the source of the pool
+	if s.Options.Cube != "" {
+		cube, _ := ParseCube(cardDB, s.Options.Cube)
+		if s.Options.CubeIntersect {
+			cube.IntersectNames(cardDB, collection)
+		}
+		collection = cube
 	}
 
 	// filter colors
-	if s.Options.ColorOptions != (ColorOptions{}) {
+	if s.Options.ColorOptions.Enabled() {
 		collection.FilterColors(cardDB, s.Options.ColorOptions)
 	}
 
@@ -163,8 +747,40 @@ func (s *Session) constructed(cardDB CardDB) {
 	}
 
 	// set filter
-	if s.Options.Set != "" {
-		collection.FilterSet(cardDB, s.Options.Set)
+	if sets := s.Options.sets(); len(sets) > 0 {
+		collection.FilterSets(cardDB, sets, s.Options.AnyPrinting)
+	}
+
+	// mana value
+	if s.Options.CMCOptions != (CMCOptions{}) {
+		collection.FilterCMC(cardDB, s.Options.CMCOptions)
+	}
+
+	// card types
+	if len(s.Options.TypeOptions.Include) > 0 || len(s.Options.TypeOptions.Exclude) > 0 {
+		collection.FilterTypes(cardDB, s.Options.TypeOptions)
+	}
+
+	// curated lists
+	if len(s.Options.Allowlist) > 0 {
+		allowed, _ := cardDB.Resolve(s.Options.Allowlist)
+		collection.FilterNames(cardDB, allowed, true)
+	}
+	if len(s.Options.Banlist) > 0 {
+		banned, _ := cardDB.Resolve(s.Options.Banlist)
+		collection.FilterNames(cardDB, banned, false)
+	}
+
+	// free-form query, already validated on session creation
+	if s.Options.Query != "" {
+		if query, err := ParseQuery(s.Options.Query); err == nil {
+			collection.FilterQuery(cardDB, query)
+		}
+	}
+
+	// cards from e.g. planeswalker decks
+	if s.Options.BoosterOnly {
+		collection.FilterBooster(cardDB)
 	}
 
 	// generally never show more than 4 per name
@@ -174,8 +790,10 @@ func (s *Session) constructed(cardDB CardDB) {
 	}
 	collection.MaxPerCard(cardDB, max)
 
-	//  write back for each player
-	for _, player := range s.Players {
-		player.SessionCollection = collection
+	// cut down to a manageable size
+	if s.Options.PoolSize > 0 {
+		collection.Sample(cardDB, s.Options.PoolSize, s.Options.PoolQuotas, rng)
 	}
+
+	return collection
 }
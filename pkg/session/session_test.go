@@ -0,0 +1,233 @@
+package session
+
+import (
+	"fmt"
+	"testing"
+)
+
+// testCards creates a card database with the given number of cards of rotating rarities and colors,
+// plus a collection containing one copy of each card
+func testCards(n int) (CardDB, Collection) {
+	colors := []string{"W", "U", "B", "R", "G"}
+
+	cardDB := CardDB{}
+	collection := Collection{}
+	for i := 0; i < n; i++ {
+		arenaID := ArenaID(fmt.Sprintf("%05d", i))
+		cardDB[arenaID] = CardData{
+			Name:          fmt.Sprintf("Card %d", i),
+			Set:           "tst",
+			Rarity:        rarities[i%len(rarities)],
+			ColorIdentity: []string{colors[i%len(colors)]},
+			InBooster:     true,
+		}
+		collection[arenaID] = 1
+	}
+	return cardDB, collection
+}
+
+// startTestSession creates a session with the given players sharing the collection and starts it
+func startTestSession(t *testing.T, cardDB CardDB, collection Collection, opts Options, playerIDs ...string) *Session {
+	t.Helper()
+
+	if err := opts.Validate(cardDB); err != nil {
+		t.Fatalf("invalid options: %v", err)
+	}
+
+	s := New(opts)
+	for _, playerID := range playerIDs {
+		s.Players[playerID] = &PlayerData{CompleteCollection: collection}
+	}
+	for _, playerID := range playerIDs {
+		if err := s.UpdatePlayer(cardDB, playerID, PlayerUpdate{Ready: true}); err != nil {
+			t.Fatalf("could not start session: %v", err)
+		}
+	}
+	if !s.Started {
+		t.Fatal("session not started")
+	}
+	return s
+}
+
+// countCards returns the number of cards in the session collections of all players
+func countCards(s *Session) int {
+	count := 0
+	for _, player := range s.Players {
+		for _, n := range player.SessionCollection {
+			count += int(n)
+		}
+	}
+	return count
+}
+
+func TestRejoin(t *testing.T) {
+	token, err := NewToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		async    bool
+		playerID string
+		token    string
+		err      error
+	}{
+		{true, "a", token, nil},
+		{true, "a", "", ErrInvalidToken},
+		{true, "a", token[1:], ErrInvalidToken},
+		{true, "b", token, ErrPlayerNotFound},
+		{false, "a", token, ErrRejoinNotAllowed},
+	}
+	for i, test := range tests {
+		s := New(Options{Mode: ModeDraft, Async: test.async})
+		s.Players["a"] = &PlayerData{Token: token}
+
+		if err := s.Rejoin(test.playerID, test.token); err != test.err {
+			t.Errorf("%d: expected %v, got %v", i, test.err, err)
+		}
+	}
+}
+
+func TestValidateBots(t *testing.T) {
+	cardDB, _ := testCards(10)
+
+	tests := []struct {
+		mode string
+		bots int
+		err  error
+	}{
+		{ModeDraft, 0, nil},
+		{ModeDraft, 7, nil},
+		{ModeDraft, 8, ErrInvalidBots},
+		{ModeDraft, -1, ErrInvalidBots},
+		{ModeChaos, 3, nil},
+		{ModeWinston, 1, ErrBotsNotSupported},
+		{ModeSealed, 1, ErrBotsNotSupported},
+		{ModeSealed, 0, nil},
+	}
+	for _, test := range tests {
+		opts := Options{Mode: test.mode, Bots: test.bots}
+		if err := opts.Validate(cardDB); err != test.err {
+			t.Errorf("%s with %d bots: expected %v, got %v", test.mode, test.bots, test.err, err)
+		}
+	}
+}
+
+func TestValidateAsync(t *testing.T) {
+	cardDB, _ := testCards(10)
+
+	tests := map[string]error{
+		ModeDraft:     nil,
+		ModeChaos:     nil,
+		ModeWinston:   ErrAsyncNotSupported,
+		ModeRochester: ErrAsyncNotSupported,
+		ModeSealed:    ErrAsyncNotSupported,
+	}
+	for mode, expected := range tests {
+		opts := Options{Mode: mode, Async: true}
+		if err := opts.Validate(cardDB); err != expected {
+			t.Errorf("%s: expected %v, got %v", mode, expected, err)
+		}
+	}
+}
+
+func TestPlayAfterPlayerLeft(t *testing.T) {
+	// the remaining players take the first thing offered, nobody runs out of time
+	moves := map[string]func(s *Session) (string, PlayerAction){
+		ModeDraft: func(s *Session) (string, PlayerAction) {
+			for _, seat := range s.Draft.Seats {
+				if !seat.Bot && len(seat.Queue) > 0 {
+					return seat.PlayerID, PlayerAction{Action: ActionPick, Card: seat.Queue[0].Cards[0]}
+				}
+			}
+			return "", PlayerAction{}
+		},
+		ModeRochester: func(s *Session) (string, PlayerAction) {
+			return s.Rochester.Turn, PlayerAction{Action: ActionPick, Card: s.Rochester.Pack.Cards[0]}
+		},
+		ModeGrid: func(s *Session) (string, PlayerAction) {
+			return s.Grid.Turn, s.Grid.fullest()
+		},
+		ModeWinston: func(s *Session) (string, PlayerAction) {
+			if !s.Winston.Looked {
+				return s.Winston.Turn, PlayerAction{Action: ActionLook}
+			}
+			return s.Winston.Turn, PlayerAction{Action: ActionTake}
+		},
+	}
+
+	for mode, move := range moves {
+		players := []string{"a", "b", "c"}
+		if mode == ModeWinston {
+			players = players[:2]
+		}
+
+		// everybody leaves once, right at the start or after a few moves
+		for _, moves := range []int{0, 1, 5} {
+			cardDB, collection := testCards(200)
+			opts := Options{Mode: mode, Seed: 1, PackOptions: PackOptions{Count: 2, Size: 5}}
+			s := startTestSession(t, cardDB, collection, opts, players...)
+
+			for i := 0; i < moves; i++ {
+				playerID, action := move(s)
+				if err := s.HandleAction(cardDB, playerID, action); err != nil {
+					t.Fatalf("%s: %v", mode, err)
+				}
+			}
+
+			s.RemovePlayer(cardDB, players[0])
+			if _, ok := s.Players[players[0]]; ok {
+				t.Fatalf("%s: player not removed", mode)
+			}
+
+			for i := 0; !s.finished(); i++ {
+				if i > 1000 {
+					t.Fatalf("%s: game did not finish after %d moves", mode, moves)
+				}
+
+				playerID, action := move(s)
+				if _, ok := s.Players[playerID]; !ok {
+					t.Fatalf("%s: waiting for %q, who left after %d moves", mode, playerID, moves)
+				}
+				if err := s.HandleAction(cardDB, playerID, action); err != nil {
+					t.Fatalf("%s: %v", mode, err)
+				}
+			}
+
+			if countCards(s) == 0 {
+				t.Errorf("%s: remaining players got no cards", mode)
+			}
+		}
+	}
+}
+
+func TestValidateCardData(t *testing.T) {
+	// test cards come without type lines and casting colors, like older card databases
+	cardDB, _ := testCards(10)
+	full := CardDB{}
+	for arenaID, card := range cardDB {
+		card.TypeLine = "Creature — Elf"
+		card.Colors = card.ColorIdentity
+		full[arenaID] = card
+	}
+
+	tests := []struct {
+		opts Options
+		err  error
+	}{
+		{Options{Query: "r:rare id:wu"}, nil},
+		{Options{Query: "t:creature"}, ErrNoTypeData},
+		{Options{TypeOptions: TypeOptions{Exclude: []string{"land"}}}, ErrNoTypeData},
+		{Options{Query: "c:wu"}, ErrNoColorData},
+		{Options{ColorOptions: ColorOptions{Green: true, Source: ColorSourceColors}}, ErrNoColorData},
+		{Options{ColorOptions: ColorOptions{Green: true, Source: ColorSourceIdentity}}, nil},
+	}
+	for _, test := range tests {
+		if err := test.opts.Validate(cardDB); err != test.err {
+			t.Errorf("%+v: expected %v, got %v", test.opts, test.err, err)
+		}
+		if err := test.opts.Validate(full); err != nil {
+			t.Errorf("%+v with full card data: %v", test.opts, err)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package session
+
+import (
+	"sort"
+	"strings"
+)
+
+func init() {
+	RegisterMode(ModeSplit, split{})
+}
+
+// split deals the pool out among the players, so that every player builds from a unique pool.
+// Cards are dealt by rarity and color, so that every player ends up with a similar share of both
+type split struct {
+	noActions
+}
+
+func (split) Validate(Options) error {
+	return nil
+}
+
+func (split) Start(s *Session, cardDB CardDB) error {
+	rng := s.rand()
+	pool := s.pool(cardDB, rng)
+
+	cards := newSupply(cardDB, pool).cards()
+	rng.Shuffle(len(cards), func(i, j int) { cards[i], cards[j] = cards[j], cards[i] })
+
+	groups := map[ArenaID]string{}
+	for _, arenaID := range cards {
+		cardDetails := cardDB[arenaID]
+		groups[arenaID] = cardDetails.Rarity + "/" + strings.Join(cardDetails.ColorIdentity, "")
+	}
+	sort.SliceStable(cards, func(i, j int) bool { return groups[cards[i]] < groups[cards[j]] })
+
+	playerIDs := s.playerIDs()
+	collections := make([]Collection, len(playerIDs))
+	for i := range collections {
+		collections[i] = Collection{}
+	}
+
+	// dealing round-robin over the sorted cards spreads every group evenly
+	for i, arenaID := range cards {
+		collections[i%len(collections)][arenaID]++
+	}
+
+	for i, playerID := range playerIDs {
+		s.Players[playerID].SessionCollection = collections[i]
+	}
+	return nil
+}
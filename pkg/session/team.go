@@ -0,0 +1,90 @@
+package session
+
+func init() {
+	RegisterMode(ModeTeamSealed, teamSealed{})
+	RegisterMode(ModeTwoHeadedGiant, teamSealed{pairs: true})
+}
+
+// teamSealed hands every team a shared set of boosters: the pack count is multiplied by the team size.
+// Players without a team play on their own
+type teamSealed struct {
+	noActions
+	packValidation
+
+	// pairs requires teams of exactly two players (Two-Headed Giant)
+	pairs bool
+}
+
+func (t teamSealed) Start(s *Session, cardDB CardDB) error {
+	teams := s.teamPlayers()
+	if t.pairs {
+		for _, team := range teams {
+			if len(team) != 2 {
+				return ErrTeamsOfTwo
+			}
+		}
+	}
+
+	rng := s.rand()
+	pool := s.pool(cardDB, rng)
+
+	collections := make([]Collection, len(teams))
+	for i, team := range teams {
+		count := s.Options.PackOptions.count(DefaultPackCount) * len(team)
+		packs, err := generatePacks(cardDB, pool, s.Options.PackOptions, s.Options.Collation, count, rng)
+		if err != nil {
+			return err
+		}
+		collections[i] = Packs(packs)
+	}
+
+	// only write back once all packs could be generated; every member sees the whole team pool
+	for i, team := range teams {
+		for _, playerID := range team {
+			s.Players[playerID].SessionCollection = collections[i].Copy()
+		}
+	}
+
+	return nil
+}
+
+// team returns the name of the player's team ("" == no team)
+func (p *PlayerData) team() string {
+	if p.Team == nil {
+		return ""
+	}
+	return *p.Team
+}
+
+// Teams returns the IDs of the players in each team. Players without a team are left out
+func (s *Session) Teams() map[string][]string {
+	teams := map[string][]string{}
+	for _, playerID := range s.playerIDs() {
+		if team := s.Players[playerID].team(); team != "" {
+			teams[team] = append(teams[team], playerID)
+		}
+	}
+	return teams
+}
+
+// teamPlayers returns all teams in a stable order. Players without a team form a team of their own
+func (s *Session) teamPlayers() [][]string {
+	var teams [][]string
+	index := map[string]int{}
+	for _, playerID := range s.playerIDs() {
+		team := s.Players[playerID].team()
+		if team == "" {
+			teams = append(teams, []string{playerID})
+			continue
+		}
+
+		i, ok := index[team]
+		if !ok {
+			i = len(teams)
+			index[team] = i
+			teams = append(teams, nil)
+		}
+		teams[i] = append(teams[i], playerID)
+	}
+	return teams
+}
@@ -0,0 +1,98 @@
+package session
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// update applies a raw lobby message, like the controller does
+func update(t *testing.T, s *Session, cardDB CardDB, playerID string, message string) error {
+	t.Helper()
+
+	var m PlayerMessage
+	if err := json.Unmarshal([]byte(message), &m); err != nil {
+		t.Fatal(err)
+	}
+	return s.UpdatePlayer(cardDB, playerID, m.PlayerUpdate)
+}
+
+func TestTeamSurvivesReady(t *testing.T) {
+	cardDB, collection := testCards(10)
+	s := New(Options{Mode: ModeTeamSealed})
+	for _, playerID := range []string{"a", "b", "c"} {
+		s.Players[playerID] = &PlayerData{CompleteCollection: collection}
+	}
+
+	update(t, s, cardDB, "a", `{"team": "x"}`)
+	update(t, s, cardDB, "a", `{"ready": false}`)
+	update(t, s, cardDB, "b", `{"team": "x"}`)
+	update(t, s, cardDB, "c", `{"team": "x"}`)
+	update(t, s, cardDB, "c", `{"team": ""}`)
+
+	expected := map[string][]string{"x": {"a", "b"}}
+	if teams := s.Teams(); !reflect.DeepEqual(teams, expected) {
+		t.Errorf("expected teams %v, got %v", expected, teams)
+	}
+}
+
+func TestTeamSealed(t *testing.T) {
+	cardDB, collection := testCards(400)
+	opts := Options{Seed: 1, PackOptions: PackOptions{Count: 2, Size: 5}}
+
+	tests := []struct {
+		mode  string
+		teams map[string]string
+		err   error
+		sizes map[string]int
+	}{
+		{ModeTeamSealed, map[string]string{"a": "x", "b": "x", "c": ""}, nil, map[string]int{"a": 20, "b": 20, "c": 10}},
+		{ModeTeamSealed, map[string]string{"a": "x", "b": "y", "c": "x"}, nil, map[string]int{"a": 20, "b": 10, "c": 20}},
+		{ModeTwoHeadedGiant, map[string]string{"a": "x", "b": "x", "c": "y", "d": "y"}, nil, map[string]int{"a": 20, "b": 20, "c": 20, "d": 20}},
+		{ModeTwoHeadedGiant, map[string]string{"a": "x", "b": "x", "c": "y"}, ErrTeamsOfTwo, nil},
+		{ModeTwoHeadedGiant, map[string]string{"a": "x", "b": "x", "c": "x"}, ErrTeamsOfTwo, nil},
+	}
+
+	for i, test := range tests {
+		opts.Mode = test.mode
+		s := New(opts)
+		for playerID := range test.teams {
+			s.Players[playerID] = &PlayerData{CompleteCollection: collection}
+		}
+		for playerID, team := range test.teams {
+			update(t, s, cardDB, playerID, `{"team": "`+team+`"}`)
+		}
+
+		// the shipped client only sends the ready flag
+		var err error
+		for playerID := range test.teams {
+			if e := update(t, s, cardDB, playerID, `{"ready": true}`); e != nil {
+				err = e
+			}
+		}
+		if err != test.err {
+			t.Fatalf("%d: expected %v, got %v", i, test.err, err)
+		}
+		if err != nil {
+			continue
+		}
+
+		for playerID, size := range test.sizes {
+			count := 0
+			for _, n := range s.Players[playerID].SessionCollection {
+				count += int(n)
+			}
+			if count != size {
+				t.Errorf("%d: %s has %d cards, expected %d", i, playerID, count, size)
+			}
+		}
+		for playerID, team := range test.teams {
+			for other, otherTeam := range test.teams {
+				shared := reflect.DeepEqual(s.Players[playerID].SessionCollection, s.Players[other].SessionCollection)
+				if team != "" && team == otherTeam && !shared {
+					t.Errorf("%d: %s and %s don't share their team pool", i, playerID, other)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpireAfterPlayerLeft(t *testing.T) {
+	tests := []struct {
+		mode    string
+		players []string
+	}{
+		{ModeDraft, []string{"a", "b", "c"}},
+		{ModeChaos, []string{"a", "b", "c"}},
+		{ModeRochester, []string{"a", "b", "c"}},
+		{ModeGrid, []string{"a", "b", "c"}},
+		{ModeWinston, []string{"a", "b"}},
+	}
+
+	for _, test := range tests {
+		cardDB, collection := testCards(200)
+		opts := Options{Mode: test.mode, Seed: 1, PickTime: 1, PackOptions: PackOptions{Count: 2, Size: 5}}
+		s := startTestSession(t, cardDB, collection, opts, test.players...)
+
+		// the last player disconnects in the middle of the game
+		left := test.players[len(test.players)-1]
+		s.RemovePlayer(cardDB, left)
+
+		now := time.Now()
+		for i := 0; len(s.Deadlines()) > 0; i++ {
+			if i > 1000 {
+				t.Fatalf("%s: game did not finish", test.mode)
+			}
+			now = now.Add(2 * time.Second)
+			if !s.Expire(cardDB, now) {
+				t.Fatalf("%s: nothing expired, deadlines %v", test.mode, s.Deadlines())
+			}
+		}
+
+		if !s.finished() {
+			t.Errorf("%s: game not finished", test.mode)
+		}
+		if countCards(s) == 0 {
+			t.Errorf("%s: remaining players got no cards", test.mode)
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package session
+
+import "time"
+
+// DefaultWinstonPoolSize is the number of cards in a Winston draft without a configured pool size
+const DefaultWinstonPoolSize = 90
+
+const winstonPiles = 3
+
+func init() {
+	RegisterMode(ModeWinston, winston{})
+}
+
+// winston is a two player draft: the active player looks at face-down piles one after another
+// and either takes a pile or passes it, adding another card from the stack to it
+type winston struct{}
+
+// Winston contains the state of a Winston draft. Only the sizes of the piles are public
+type Winston struct {
+	Players  []string  `firestore:"players" json:"players"`
+	Turn     string    `firestore:"turn" json:"turn"`
+	Pile     int       `firestore:"pile" json:"pile"`
+	Looked   bool      `firestore:"looked" json:"looked"`
+	Sizes    []int     `firestore:"sizes" json:"sizes"`
+	Stack    int       `firestore:"stack" json:"stack"`
+	Deadline time.Time `firestore:"deadline" json:"deadline"`
+	Finished bool      `firestore:"finished" json:"finished"`
+
+	Piles []Pack    `firestore:"piles" json:"-"`
+	Cards []ArenaID `firestore:"cards" json:"-"`
+	Log   []PickLog `firestore:"log" json:"-"`
+}
+
+// WinstonView is the part of a Winston draft a single player is allowed to see
+type WinstonView struct {
+	Turn  bool      `json:"turn"`
+	Pile  *Pack     `json:"pile"`
+	Picks []ArenaID `json:"picks"`
+}
+
+func (w *Winston) copy() *Winston {
+	if w == nil {
+		return nil
+	}
+
+	out := *w
+	out.Sizes = append([]int(nil), w.Sizes...)
+	out.Piles = append([]Pack(nil), w.Piles...)
+	out.Cards = append([]ArenaID(nil), w.Cards...)
+	out.Log = append([]PickLog(nil), w.Log...)
+	return &out
+}
+
+func (winston) Validate(Options) error {
+	return nil
+}
+
+// Start shuffles the pool into a stack and lays out the piles
+func (winston) Start(s *Session, cardDB CardDB) error {
+	if len(s.Players) != 2 {
+		return ErrTwoPlayersOnly
+	}
+
+	rng := s.rand()
+	pool := s.pool(cardDB, rng)
+
+	cards := newSupply(cardDB, pool).cards()
+	rng.Shuffle(len(cards), func(i, j int) { cards[i], cards[j] = cards[j], cards[i] })
+
+	// an explicit pool size was already applied to the pool
+	if s.Options.PoolSize == 0 && len(cards) > DefaultWinstonPoolSize {
+		cards = cards[:DefaultWinstonPoolSize]
+	}
+	if len(cards) < winstonPiles {
+		return ErrNotEnoughCards
+	}
+
+	w := &Winston{
+		Players: s.playerIDs(),
+		Cards:   cards,
+		Piles:   make([]Pack, winstonPiles),
+	}
+	for i := range w.Piles {
+		w.Piles[i].Cards = []ArenaID{w.draw()}
+	}
+	w.Turn = w.Players[rng.Intn(len(w.Players))]
+	w.Deadline = deadline(s.Options.pickTime(), time.Now())
+	w.update()
+
+	for _, player := range s.Players {
+		player.SessionCollection = Collection{}
+	}
+
+	s.Winston = w
+	return nil
+}
+
+// Finished checks if all picks are made
+func (winston) Finished(s *Session) bool {
+	return s.Winston != nil && s.Winston.Finished
+}
+
+// PickLog returns all piles taken so far
+func (winston) PickLog(s *Session) []PickLog {
+	if s.Winston == nil {
+		return nil
+	}
+	return s.Winston.Log
+}
+
+// HandleAction lets the active player look at, take or pass the current pile
+func (winston) HandleAction(s *Session, cardDB CardDB, playerID string, action PlayerAction) error {
+	w := s.Winston
+	if w.Finished {
+		return ErrDraftFinished
+	}
+	if w.Turn != playerID {
+		return ErrNotYourTurn
+	}
+
+	now := time.Now()
+	if err := w.act(s, action, now); err != nil {
+		return err
+	}
+
+	w.standIn(s, now)
+	return nil
+}
+
+// Expire takes the current pile for the active player once their time is up
+func (winston) Expire(s *Session, cardDB CardDB, now time.Time) bool {
+	w := s.Winston
+	if w == nil || w.Finished || w.Deadline.IsZero() || now.Before(w.Deadline) {
+		return false
+	}
+
+	w.Looked = true
+	if w.act(s, PlayerAction{Action: ActionTake}, now) != nil {
+		return false
+	}
+
+	w.standIn(s, now)
+	return true
+}
+
+// Leave takes the first pile for the player whenever it is their turn
+func (winston) Leave(s *Session, cardDB CardDB, playerID string, now time.Time) {
+	if s.Winston != nil {
+		s.Winston.standIn(s, now)
+	}
+}
+
+// Deadlines returns when the active player runs out of time
+func (winston) Deadlines(s *Session) map[string]time.Time {
+	w := s.Winston
+	if w == nil || w.Finished || w.Deadline.IsZero() {
+		return nil
+	}
+	return map[string]time.Time{w.Turn: w.Deadline}
+}
+
+// act applies an action of the active player. The clock restarts whenever the turn changes
+func (w *Winston) act(s *Session, action PlayerAction, now time.Time) error {
+	playerID := w.Turn
+
+	switch action.Action {
+	case ActionLook:
+		w.Looked = true
+
+	case ActionTake:
+		if !w.Looked {
+			return ErrNotLooked
+		}
+		w.take(s, playerID, w.Pile, w.Piles[w.Pile].Cards, now)
+		w.Piles[w.Pile].Cards = nil
+		if len(w.Cards) > 0 {
+			w.Piles[w.Pile].Cards = []ArenaID{w.draw()}
+		}
+		w.endTurn()
+
+	case ActionPass:
+		if !w.Looked {
+			return ErrNotLooked
+		}
+
+		next := w.nextPile(w.Pile)
+		if next < 0 && len(w.Cards) < 2 {
+			// passing the last pile needs one card for the pile and one to take blindly
+			return ErrMustTake
+		}
+
+		if len(w.Cards) > 0 {
+			w.Piles[w.Pile].Cards = append(w.Piles[w.Pile].Cards, w.draw())
+		}
+
+		if next < 0 {
+			// passed on the last pile: take the top card of the stack instead
+			w.take(s, playerID, len(w.Piles), []ArenaID{w.draw()}, now)
+			w.endTurn()
+			break
+		}
+
+		w.Pile = next
+		w.Looked = false
+
+	default:
+		return ErrInvalidAction
+	}
+
+	switch {
+	case w.Finished:
+		w.Deadline = time.Time{}
+	case w.Turn != playerID:
+		w.Deadline = deadline(s.Options.pickTime(), now)
+	}
+
+	w.update()
+	return nil
+}
+
+// View returns the current pile to the active player once they have looked at it
+func (winston) View(s *Session, playerID string) interface{} {
+	w := s.Winston
+	if w == nil {
+		return nil
+	}
+
+	player, ok := s.Players[playerID]
+	if !ok {
+		return nil
+	}
+
+	view := &WinstonView{Turn: w.Turn == playerID}
+	for _, arenaID := range player.SessionCollection.ids() {
+		for i := byte(0); i < player.SessionCollection[arenaID]; i++ {
+			view.Picks = append(view.Picks, arenaID)
+		}
+	}
+	if view.Turn && w.Looked && !w.Finished {
+		view.Pile = &w.Piles[w.Pile]
+	}
+
+	return view
+}
+
+// standIn takes the current pile for players who left, until it is the turn of a player still there
+func (w *Winston) standIn(s *Session, now time.Time) {
+	for !w.Finished {
+		if _, ok := s.Players[w.Turn]; ok {
+			return
+		}
+
+		w.Looked = true
+		if w.act(s, PlayerAction{Action: ActionTake}, now) != nil {
+			return
+		}
+	}
+}
+
+// draw removes the top card of the stack
+func (w *Winston) draw() ArenaID {
+	arenaID := w.Cards[0]
+	w.Cards = w.Cards[1:]
+	return arenaID
+}
+
+// take gives the cards of a pile to the player and logs them. The stack is the pile after the last one
+func (w *Winston) take(s *Session, playerID string, pile int, cards []ArenaID, now time.Time) {
+	w.Log = append(w.Log, PickLog{
+		PlayerID: playerID,
+		Pack:     pile,
+		Pick:     picks(w.Log, playerID),
+		Offered:  cards,
+		Cards:    cards,
+		Time:     now,
+	})
+	s.give(playerID, cards...)
+}
+
+// nextPile returns the next non-empty pile after i (-1 == none)
+func (w *Winston) nextPile(i int) int {
+	for next := i + 1; next < len(w.Piles); next++ {
+		if len(w.Piles[next].Cards) > 0 {
+			return next
+		}
+	}
+	return -1
+}
+
+// endTurn hands the turn to the other player, who starts at the first non-empty pile
+func (w *Winston) endTurn() {
+	for _, playerID := range w.Players {
+		if playerID != w.Turn {
+			w.Turn = playerID
+			break
+		}
+	}
+
+	w.Looked = false
+	w.Pile = w.nextPile(-1)
+	if w.Pile < 0 {
+		w.Pile = 0
+		w.Finished = true
+	}
+}
+
+// update refreshes the public pile and stack sizes
+func (w *Winston) update() {
+	w.Sizes = make([]int, len(w.Piles))
+	for i, pile := range w.Piles {
+		w.Sizes[i] = len(pile.Cards)
+	}
+	w.Stack = len(w.Cards)
+}
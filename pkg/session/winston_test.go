@@ -0,0 +1,102 @@
+package session
+
+import "testing"
+
+func TestWinstonPlaysToFinish(t *testing.T) {
+	for _, size := range []int{3, 4, 5, 6, 12, 30} {
+		cardDB, collection := testCards(size)
+		s := startTestSession(t, cardDB, collection, Options{Mode: ModeWinston, Seed: 1}, "a", "b")
+
+		// always pass, so every pile grows and the last pile is passed whenever possible
+		for turns := 0; !s.Winston.Finished; turns++ {
+			if turns > 10*size {
+				t.Fatalf("size %d: draft did not finish", size)
+			}
+
+			playerID := s.Winston.Turn
+			if err := s.HandleAction(cardDB, playerID, PlayerAction{Action: ActionLook}); err != nil {
+				t.Fatalf("size %d: look: %v", size, err)
+			}
+
+			err := s.HandleAction(cardDB, playerID, PlayerAction{Action: ActionPass})
+			if err == ErrMustTake {
+				err = s.HandleAction(cardDB, playerID, PlayerAction{Action: ActionTake})
+			}
+			if err != nil {
+				t.Fatalf("size %d: %v", size, err)
+			}
+		}
+
+		if got := countCards(s); got != size {
+			t.Errorf("size %d: %d cards drafted", size, got)
+		}
+	}
+}
+
+func TestWinstonPassLastPile(t *testing.T) {
+	cardDB, collection := testCards(6)
+	s := startTestSession(t, cardDB, collection, Options{Mode: ModeWinston, Seed: 1}, "a", "b")
+	w := s.Winston
+	playerID := w.Turn
+
+	pass := func() error {
+		if err := s.HandleAction(cardDB, playerID, PlayerAction{Action: ActionLook}); err != nil {
+			return err
+		}
+		return s.HandleAction(cardDB, playerID, PlayerAction{Action: ActionPass})
+	}
+
+	// three piles of one card, three cards on the stack
+	if err := pass(); err != nil {
+		t.Fatal(err)
+	}
+	if err := pass(); err != nil {
+		t.Fatal(err)
+	}
+
+	// one card left: it can't go onto the pile and be taken blindly at the same time
+	if err := pass(); err != ErrMustTake {
+		t.Fatalf("expected %v, got %v", ErrMustTake, err)
+	}
+	if w.Stack != 1 || w.Turn != playerID {
+		t.Fatalf("failed pass changed the draft: stack %d, turn %s", w.Stack, w.Turn)
+	}
+
+	if err := s.HandleAction(cardDB, playerID, PlayerAction{Action: ActionTake}); err != nil {
+		t.Fatal(err)
+	}
+	if w.Turn == playerID {
+		t.Error("turn did not change after taking a pile")
+	}
+}
+
+func TestWinstonTurns(t *testing.T) {
+	cardDB, collection := testCards(12)
+	s := startTestSession(t, cardDB, collection, Options{Mode: ModeWinston, Seed: 1}, "a", "b")
+	playerID := s.Winston.Turn
+
+	other := "a"
+	if playerID == other {
+		other = "b"
+	}
+
+	tests := []struct {
+		playerID string
+		action   string
+		err      error
+	}{
+		{other, ActionLook, ErrNotYourTurn},
+		{playerID, ActionTake, ErrNotLooked},
+		{playerID, ActionPass, ErrNotLooked},
+		{playerID, ActionPick, ErrInvalidAction},
+		{playerID, ActionLook, nil},
+		{playerID, ActionTake, nil},
+		{playerID, ActionLook, ErrNotYourTurn},
+	}
+	for i, test := range tests {
+		err := s.HandleAction(cardDB, test.playerID, PlayerAction{Action: test.action})
+		if err != test.err {
+			t.Errorf("%d: %s %s: expected %v, got %v", i, test.playerID, test.action, test.err, err)
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package mem
 
 import (
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/kjeisy/arenawithfriends/pkg/session"
 	"github.com/lithammer/shortuuid"
@@ -11,6 +13,7 @@ import (
 type Store struct {
 	mutex    sync.RWMutex
 	sessions map[string]*session.Session
+	cubes    map[string]session.Cube
 }
 
 // New initializes a new memory store
@@ -18,6 +21,7 @@ func New() *Store {
 	return &Store{
 		mutex:    sync.RWMutex{},
 		sessions: map[string]*session.Session{},
+		cubes:    map[string]session.Cube{},
 	}
 }
 
@@ -34,20 +38,22 @@ func (st *Store) CreateSession(opts session.Options) (string, error) {
 		}
 	}
 
-	st.sessions[id] = &session.Session{
-		Options: opts,
-		Players: map[string]*session.PlayerData{},
-	}
+	st.sessions[id] = session.New(opts)
 
 	return id, nil
 }
 
-// GetSession checks the data for the given session (nil = not found)
+// GetSession checks the data for the given session (nil = not found).
+// All returned sessions are copies, the stored ones may only be touched while holding the lock
 func (st *Store) GetSession(id string) (*session.Session, error) {
 	st.mutex.RLock()
 	defer st.mutex.RUnlock()
 
-	return st.sessions[id], nil
+	s := st.sessions[id]
+	if s == nil {
+		return nil, nil
+	}
+	return s.Copy(), nil
 }
 
 // AddPlayer adds the given PlayerData to the session (nil output == session not found)
@@ -61,7 +67,7 @@ func (st *Store) AddPlayer(sessionID string, playerRegistration session.PlayerRe
 	}
 
 	if s.Started {
-		return "", s, nil
+		return "", s.Copy(), nil
 	}
 
 	var playerID string
@@ -72,12 +78,18 @@ func (st *Store) AddPlayer(sessionID string, playerRegistration session.PlayerRe
 		}
 	}
 
+	token, err := session.NewToken()
+	if err != nil {
+		return "", nil, err
+	}
+
 	s.Players[playerID] = &session.PlayerData{
 		PlayerName:         playerRegistration.PlayerName,
 		CompleteCollection: playerRegistration.Collection,
+		Token:              token,
 	}
 
-	return playerID, s, nil
+	return playerID, s.Copy(), nil
 }
 
 // UpdatePlayer sets
@@ -90,27 +102,128 @@ func (st *Store) UpdatePlayer(cardDB session.CardDB, sessionID string, playerID
 		return nil, nil
 	}
 
-	session.UpdatePlayer(cardDB, playerID, update)
+	err := session.UpdatePlayer(cardDB, playerID, update)
+	return session.Copy(), err
+}
+
+// HandleAction applies an in-game action of a player
+func (st *Store) HandleAction(cardDB session.CardDB, sessionID string, playerID string, action session.PlayerAction) (*session.Session, error) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	session := st.sessions[sessionID]
+	if session == nil {
+		return nil, nil
+	}
+
+	err := session.HandleAction(cardDB, playerID, action)
+	return session.Copy(), err
+}
+
+// Expire makes all moves whose time is up, returns if the session changed
+func (st *Store) Expire(cardDB session.CardDB, sessionID string) (*session.Session, bool, error) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
 
-	return session, nil
+	session := st.sessions[sessionID]
+	if session == nil {
+		return nil, false, nil
+	}
+
+	changed := session.Expire(cardDB, time.Now())
+	return session.Copy(), changed, nil
 }
 
-// RemovePlayer removes a player
-func (st *Store) RemovePlayer(sessionID string, playerID string) *session.Session {
+// RemovePlayer removes a player, a started game goes on without them
+func (st *Store) RemovePlayer(cardDB session.CardDB, sessionID string, playerID string) *session.Session {
 	st.mutex.Lock()
 	defer st.mutex.Unlock()
 
 	session, ok := st.sessions[sessionID]
 	if !ok {
-		return session
+		return nil
 	}
 
-	session.RemovePlayer(playerID)
+	session.RemovePlayer(cardDB, playerID)
 
 	if len(session.Players) == 0 {
 		delete(st.sessions, sessionID)
 		return nil
 	}
 
-	return session
+	return session.Copy()
+}
+
+// CreateCube saves a new cube
+func (st *Store) CreateCube(cube session.Cube) (string, error) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	var id string
+	for {
+		id = shortuuid.New()
+		if _, ok := st.cubes[id]; !ok {
+			break
+		}
+	}
+
+	cube.ID = id
+	st.cubes[id] = cube
+
+	return id, nil
+}
+
+// ListCubes returns all saved cubes, sorted by name
+func (st *Store) ListCubes() ([]session.Cube, error) {
+	st.mutex.RLock()
+	defer st.mutex.RUnlock()
+
+	cubes := make([]session.Cube, 0, len(st.cubes))
+	for _, cube := range st.cubes {
+		cubes = append(cubes, cube)
+	}
+	sort.Slice(cubes, func(i, j int) bool { return cubes[i].Name < cubes[j].Name })
+
+	return cubes, nil
+}
+
+// GetCube returns the cube with the given ID (nil = not found)
+func (st *Store) GetCube(id string) (*session.Cube, error) {
+	st.mutex.RLock()
+	defer st.mutex.RUnlock()
+
+	cube, ok := st.cubes[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return &cube, nil
+}
+
+// UpdateCube replaces the cube with the given ID (nil = not found)
+func (st *Store) UpdateCube(id string, cube session.Cube) (*session.Cube, error) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	if _, ok := st.cubes[id]; !ok {
+		return nil, nil
+	}
+
+	cube.ID = id
+	st.cubes[id] = cube
+
+	return &cube, nil
+}
+
+// DeleteCube removes the cube with the given ID (false = not found)
+func (st *Store) DeleteCube(id string) (bool, error) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	if _, ok := st.cubes[id]; !ok {
+		return false, nil
+	}
+
+	delete(st.cubes, id)
+	return true, nil
 }
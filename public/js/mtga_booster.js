@@ -51,6 +51,7 @@ var app = new Vue({
 	data: {
 		Session: sessionStorage.getItem("sessionid"),
 		Player: null,
+		Token: null,
 		SessionDetails: null,
 		CardPool: null,
 		Picks: null,
@@ -302,6 +303,7 @@ var app = new Vue({
 		},
 		clear_registration() {
 			this.Player = null;
+			this.Token = null;
 			this.SessionDetails = null;
 
 			if ( this.websocket ) {
@@ -352,6 +354,7 @@ var app = new Vue({
 					if (!app.Player) {
 						if (rep['id']) {
 							app.Player = rep['id']
+							app.Token = rep['token']
 							return
 						}
 						alert("Error: no ID received")
@@ -359,6 +362,11 @@ var app = new Vue({
 						return
 					}
 
+					// in-game frames (views, remaining time) are not handled here yet
+					if (rep['type']) {
+						return
+					}
+
 					// subsequent updates: lobby updates
 					app.SessionDetails = rep
 					if (app.SessionDetails['started']) {
@@ -384,7 +392,7 @@ var app = new Vue({
 				return
 			}
 
-			fetch(API + "/" + this.Session + "/players/" + this.Player + "/collection").then(function (response) {
+			fetch(API + "/" + this.Session + "/players/" + this.Player + "/collection?token=" + encodeURIComponent(this.Token)).then(function (response) {
 				try {
 					response.json().then(function (rep) {
 						if (rep['error']) {